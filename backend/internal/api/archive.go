@@ -0,0 +1,80 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"project-management-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ArchiveProject 归档项目，归档后默认不在项目列表中显示
+func (h *Handler) ArchiveProject(c *gin.Context) {
+	h.setProjectArchived(c, true)
+}
+
+// UnarchiveProject 取消归档
+func (h *Handler) UnarchiveProject(c *gin.Context) {
+	h.setProjectArchived(c, false)
+}
+
+func (h *Handler) setProjectArchived(c *gin.Context, archived bool) {
+	projectID := c.Param("projectId")
+
+	project, err := h.getProjectByID(projectID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if archived && project.ArchivedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Project is already archived"})
+		return
+	}
+	if !archived && project.ArchivedAt == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Project is not archived"})
+		return
+	}
+
+	var query string
+	var args []interface{}
+	if archived {
+		userID, _, _, _ := middleware.GetCurrentUser(c)
+		query = `
+			UPDATE projects
+			SET archived_at = CURRENT_TIMESTAMP, archived_by = $2, version = version + 1
+			WHERE id = $1 AND archived_at IS NULL AND deleted_at IS NULL`
+		args = []interface{}{projectID, userID}
+	} else {
+		query = `
+			UPDATE projects
+			SET archived_at = NULL, archived_by = NULL, version = version + 1
+			WHERE id = $1 AND archived_at IS NOT NULL AND deleted_at IS NULL`
+		args = []interface{}{projectID}
+	}
+
+	result, err := h.db.Exec(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		// 并发请求已修改了归档状态
+		h.respondVersionConflict(c, projectID)
+		return
+	}
+
+	project, err = h.getProjectByID(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("ETag", projectETag(project.Version))
+	c.JSON(http.StatusOK, project)
+}
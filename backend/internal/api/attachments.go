@@ -0,0 +1,290 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"project-management-backend/internal/middleware"
+	"project-management-backend/internal/models"
+	"project-management-backend/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AttachmentConfig 附件存储后端与上传限制
+type AttachmentConfig struct {
+	Storage      storage.Storage
+	MaxSize      int64    // 单个文件大小上限（字节），0 表示不限制
+	AllowedTypes []string // 允许的 MIME 类型，支持 image/* 形式，* 表示不限制；为空时不限制
+}
+
+// allows 判断识别出的 MIME 类型是否允许上传
+func (cfg AttachmentConfig) allows(mimeType string) bool {
+	if len(cfg.AllowedTypes) == 0 {
+		return true
+	}
+	for _, allowed := range cfg.AllowedTypes {
+		if allowed == "*" || allowed == mimeType {
+			return true
+		}
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mimeType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// attachmentColumns 构成附件的 project_attachments 列，与 scanAttachment 的扫描顺序一致
+const attachmentColumns = "id, project_id, comment_id, file_name, size, mime_type, checksum, uploaded_by, created_at"
+
+// scanAttachment 扫描一行附件数据
+func scanAttachment(row rowScanner) (models.Attachment, error) {
+	var a models.Attachment
+	err := row.Scan(&a.ID, &a.ProjectID, &a.CommentID, &a.FileName, &a.Size, &a.MimeType, &a.Checksum, &a.UploadedBy, &a.CreatedAt)
+	return a, err
+}
+
+// sniffMimeType 根据文件开头的内容识别 MIME 类型，去掉 charset 等参数
+func sniffMimeType(head []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+// hasComment 判断项目中是否存在指定评论
+func hasComment(project models.Project, commentID string) bool {
+	for _, comment := range project.Comments {
+		if comment.ID == commentID {
+			return true
+		}
+	}
+	return false
+}
+
+// loadAttachment 读取项目的单个附件
+func (h *Handler) loadAttachment(projectID, attachmentID string) (models.Attachment, error) {
+	return scanAttachment(h.db.QueryRow(
+		"SELECT "+attachmentColumns+" FROM project_attachments WHERE id = $1 AND project_id = $2",
+		attachmentID, projectID))
+}
+
+// respondAttachmentError 附件不存在时返回 404，其他错误返回 500
+func respondAttachmentError(c *gin.Context, err error) {
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+	} else {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// GetProjectAttachments 获取项目的附件列表，commentId 可筛选某条评论的附件
+func (h *Handler) GetProjectAttachments(c *gin.Context) {
+	projectID := c.Param("projectId")
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	query := "SELECT " + attachmentColumns + " FROM project_attachments WHERE project_id = $1"
+	args := []interface{}{projectID}
+	if commentID := c.Query("commentId"); commentID != "" {
+		query += " AND comment_id = $2"
+		args = append(args, commentID)
+	}
+
+	rows, err := h.db.Query(query+" ORDER BY created_at, id", args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	attachments := []models.Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		attachments = append(attachments, a)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// UploadProjectAttachment 上传附件（multipart 字段 file，可选 commentId 关联到评论）
+// MIME 类型根据文件内容识别，超出大小或类型限制时拒绝
+func (h *Handler) UploadProjectAttachment(c *gin.Context) {
+	projectID := c.Param("projectId")
+	// 先校验项目，避免为不存在的项目解析整个请求体
+	project, err := h.getProjectByID(projectID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	maxSize := h.attachments.MaxSize
+	if maxSize > 0 {
+		// 额外预留 1MB 给 multipart 边界和其他表单字段
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("File exceeds the %d bytes limit", maxSize)})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file is required: " + err.Error()})
+		}
+		return
+	}
+	if maxSize > 0 && fileHeader.Size > maxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("File exceeds the %d bytes limit", maxSize)})
+		return
+	}
+	if fileHeader.Size == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is empty"})
+		return
+	}
+
+	userID, _, _, _ := middleware.GetCurrentUser(c)
+	attachment := models.Attachment{
+		ID:        newID("att"),
+		ProjectID: projectID,
+		FileName:  strings.TrimSpace(fileHeader.Filename),
+		Size:      fileHeader.Size,
+	}
+	if attachment.FileName == "" {
+		attachment.FileName = attachment.ID
+	}
+	if userID != "" {
+		attachment.UploadedBy = &userID
+	}
+	if commentID := strings.TrimSpace(c.PostForm("commentId")); commentID != "" {
+		if !hasComment(project, commentID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Comment not found in project"})
+			return
+		}
+		attachment.CommentID = &commentID
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	// http.DetectContentType 最多参考前 512 字节
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	head = head[:n]
+	attachment.MimeType = sniffMimeType(head)
+	if !h.attachments.allows(attachment.MimeType) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "File type " + attachment.MimeType + " is not allowed"})
+		return
+	}
+
+	hash := sha256.New()
+	content := io.TeeReader(io.MultiReader(bytes.NewReader(head), file), hash)
+	if err := h.attachments.Storage.Save(attachment.ID, content); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store file: " + err.Error()})
+		return
+	}
+	attachment.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	_, err = h.db.Exec(`
+		INSERT INTO project_attachments (id, project_id, comment_id, file_name, size, mime_type, checksum, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		attachment.ID, attachment.ProjectID, attachment.CommentID, attachment.FileName, attachment.Size,
+		attachment.MimeType, attachment.Checksum, attachment.UploadedBy)
+	if err != nil {
+		h.attachments.Storage.Delete(attachment.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save attachment: " + err.Error()})
+		return
+	}
+
+	saved, err := h.loadAttachment(projectID, attachment.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, saved)
+}
+
+// DownloadProjectAttachment 下载附件内容
+func (h *Handler) DownloadProjectAttachment(c *gin.Context) {
+	projectID := c.Param("projectId")
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	attachment, err := h.loadAttachment(projectID, c.Param("attachmentId"))
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	file, err := h.attachments.Storage.Open(attachment.ID)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment file not found in storage"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.MimeType, file, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"ETag":                   `"` + attachment.Checksum + `"`,
+	})
+}
+
+// DeleteProjectAttachment 删除附件，先删除存储中的文件再删除元数据，失败时可重试
+func (h *Handler) DeleteProjectAttachment(c *gin.Context) {
+	projectID := c.Param("projectId")
+	attachmentID := c.Param("attachmentId")
+
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	if _, err := h.loadAttachment(projectID, attachmentID); err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+	if err := h.attachments.Storage.Delete(attachmentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete file: " + err.Error()})
+		return
+	}
+	if _, err := h.db.Exec("DELETE FROM project_attachments WHERE id = $1 AND project_id = $2", attachmentID, projectID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,50 @@
+package api
+
+import "testing"
+
+func TestAttachmentConfigAllows(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowed  []string
+		mimeType string
+		want     bool
+	}{
+		{"no restriction", nil, "text/html", true},
+		{"exact match", []string{"application/pdf"}, "application/pdf", true},
+		{"not listed", []string{"application/pdf"}, "text/html", false},
+		{"wildcard subtype", []string{"image/*"}, "image/png", true},
+		{"wildcard other type", []string{"image/*"}, "text/plain", false},
+		{"wildcard needs slash", []string{"image/*"}, "imagex/png", false},
+		{"allow all", []string{"*"}, "text/html", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := AttachmentConfig{AllowedTypes: tt.allowed}
+			if got := cfg.allows(tt.mimeType); got != tt.want {
+				t.Errorf("allows(%q) with %v = %v, want %v", tt.mimeType, tt.allowed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSniffMimeType(t *testing.T) {
+	tests := []struct {
+		name string
+		head []byte
+		want string
+	}{
+		{"png", []byte("\x89PNG\x0D\x0A\x1A\x0A"), "image/png"},
+		{"pdf", []byte("%PDF-1.7"), "application/pdf"},
+		{"text drops charset", []byte("hello"), "text/plain"},
+		{"html", []byte("<html><body>"), "text/html"},
+		{"zip", []byte("PK\x03\x04"), "application/zip"},
+		{"unknown binary", []byte{0x00, 0x01, 0x02, 0xff}, "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sniffMimeType(tt.head); got != tt.want {
+				t.Errorf("sniffMimeType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
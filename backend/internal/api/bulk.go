@@ -0,0 +1,288 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"project-management-backend/internal/middleware"
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBulkProjects 单次批量操作最多处理的项目数
+const maxBulkProjects = 200
+
+// 批量操作类型
+const (
+	bulkSetStatus      = "setStatus"
+	bulkSetPriority    = "setPriority"
+	bulkAddFollower    = "addFollower"
+	bulkRemoveFollower = "removeFollower"
+	bulkAddMember      = "addMember"
+	bulkRemoveMember   = "removeMember"
+	bulkLinkKR         = "linkKr"
+)
+
+// bulkProjectRequest 批量操作请求，对所有项目应用同一个操作
+// Atomic 为 true 时任一项目失败则全部回滚
+type bulkProjectRequest struct {
+	ProjectIDs []string `json:"projectIds" binding:"required"`
+	Operation  string   `json:"operation" binding:"required"`
+	Value      string   `json:"value"`  // setStatus / setPriority / linkKr 的目标值
+	UserID     string   `json:"userId"` // 关注人或成员的用户ID
+	Role       string   `json:"role"`   // addMember / removeMember 的角色键
+	Atomic     bool     `json:"atomic"`
+}
+
+// bulkProjectResult 单个项目的操作结果
+type bulkProjectResult struct {
+	ProjectID string `json:"projectId"`
+	Success   bool   `json:"success"`
+	Version   int    `json:"version,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// validate 校验操作类型与所需参数，roleNames 为已定义的角色
+func (req *bulkProjectRequest) validate(roleNames map[string]string) error {
+	if len(req.ProjectIDs) == 0 {
+		return fmt.Errorf("projectIds cannot be empty")
+	}
+	if len(req.ProjectIDs) > maxBulkProjects {
+		return fmt.Errorf("at most %d projects per request", maxBulkProjects)
+	}
+
+	switch req.Operation {
+	case bulkSetStatus, bulkSetPriority, bulkLinkKR:
+		if strings.TrimSpace(req.Value) == "" {
+			return fmt.Errorf("value is required for %s", req.Operation)
+		}
+	case bulkAddFollower, bulkRemoveFollower:
+		if strings.TrimSpace(req.UserID) == "" {
+			return fmt.Errorf("userId is required for %s", req.Operation)
+		}
+	case bulkAddMember, bulkRemoveMember:
+		if strings.TrimSpace(req.UserID) == "" {
+			return fmt.Errorf("userId is required for %s", req.Operation)
+		}
+		if _, ok := roleNames[req.Role]; !ok {
+			return fmt.Errorf("invalid role: %s", req.Role)
+		}
+	default:
+		return fmt.Errorf("unsupported operation: %s", req.Operation)
+	}
+	return nil
+}
+
+// apply 将操作应用到项目上，重复添加或移除不存在的对象视为成功
+func (req *bulkProjectRequest) apply(p *models.Project) {
+	switch req.Operation {
+	case bulkSetStatus:
+		p.Status = req.Value
+	case bulkSetPriority:
+		p.Priority = req.Value
+	case bulkLinkKR:
+		p.KeyResultIds = appendUnique(p.KeyResultIds, req.Value)
+	case bulkAddFollower:
+		p.Followers = appendUnique(p.Followers, req.UserID)
+	case bulkRemoveFollower:
+		p.Followers = removeString(p.Followers, req.UserID)
+	case bulkAddMember:
+		role := roleMembers(p, req.Role)
+		for _, member := range role {
+			if member.UserID == req.UserID {
+				return
+			}
+		}
+		updated := append(models.Role{}, role...)
+		setRoleMembers(p, req.Role, append(updated, models.TeamMember{UserID: req.UserID, TimeSlots: []models.TimeSlot{}}))
+	case bulkRemoveMember:
+		updated := models.Role{}
+		for _, member := range roleMembers(p, req.Role) {
+			if member.UserID != req.UserID {
+				updated = append(updated, member)
+			}
+		}
+		setRoleMembers(p, req.Role, updated)
+	}
+}
+
+// BulkUpdateProjects 对多个项目批量执行同一操作，返回每个项目的结果
+func (h *Handler) BulkUpdateProjects(c *gin.Context) {
+	var req bulkProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	roleNames, err := h.projectRoleNames()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load roles: " + err.Error()})
+		return
+	}
+	if err := req.validate(roleNames); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 状态变更需符合状态流转定义
+	workflow, err := h.loadWorkflow()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workflow: " + err.Error()})
+		return
+	}
+
+	userID, _, _, _ := middleware.GetCurrentUser(c)
+	results := make([]bulkProjectResult, 0, len(req.ProjectIDs))
+	failed := 0
+
+	if req.Atomic {
+		// 原子模式：所有项目在同一事务中更新，任一失败全部回滚
+		tx, err := h.db.Begin()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+			return
+		}
+		defer tx.Rollback()
+
+		for _, projectID := range req.ProjectIDs {
+			result := h.bulkUpdateProject(tx, &req, workflow, projectID, userID)
+			if !result.Success {
+				failed++
+			}
+			results = append(results, result)
+		}
+
+		if failed > 0 {
+			tx.Rollback()
+			for i := range results {
+				if results[i].Success {
+					results[i].Success = false
+					results[i].Version = 0
+					results[i].Error = "rolled back"
+				}
+			}
+			c.JSON(http.StatusBadRequest, gin.H{
+				"results":    results,
+				"succeeded":  0,
+				"failed":     len(results),
+				"rolledBack": true,
+			})
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+			return
+		}
+	} else {
+		// 非原子模式：每个项目独立提交
+		for _, projectID := range req.ProjectIDs {
+			result := h.bulkUpdateProjectInTx(&req, workflow, projectID, userID)
+			if !result.Success {
+				failed++
+			}
+			results = append(results, result)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":    results,
+		"succeeded":  len(results) - failed,
+		"failed":     failed,
+		"rolledBack": false,
+	})
+}
+
+// bulkUpdateProjectInTx 在独立事务中更新单个项目
+func (h *Handler) bulkUpdateProjectInTx(req *bulkProjectRequest, workflow *models.StatusWorkflow, projectID, userID string) bulkProjectResult {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return bulkProjectResult{ProjectID: projectID, Error: "Failed to start transaction"}
+	}
+	defer tx.Rollback()
+
+	result := h.bulkUpdateProject(tx, req, workflow, projectID, userID)
+	if !result.Success {
+		return result
+	}
+	if err := tx.Commit(); err != nil {
+		return bulkProjectResult{ProjectID: projectID, Error: "Failed to commit transaction"}
+	}
+	return result
+}
+
+// bulkUpdateProject 锁定并更新单个项目，仅同步被移除成员的时段数据
+func (h *Handler) bulkUpdateProject(tx *sql.Tx, req *bulkProjectRequest, workflow *models.StatusWorkflow, projectID, userID string) bulkProjectResult {
+	result := bulkProjectResult{ProjectID: projectID}
+
+	existing, err := scanProject(tx.QueryRow(
+		"SELECT "+projectColumns+" FROM projects WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", projectID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			result.Error = "Project not found"
+		} else {
+			result.Error = err.Error()
+		}
+		return result
+	}
+
+	before := existing
+	req.apply(&existing)
+
+	if transitionErr := checkStatusTransition(workflow, before.Status, existing.Status); transitionErr != nil {
+		result.Error = transitionErr.Error()
+		return result
+	}
+
+	if err := h.recordProjectChanges(&before, &existing, userID, time.Now()); err != nil {
+		result.Error = "Failed to load users: " + err.Error()
+		return result
+	}
+
+	saved, err := saveProject(tx, &existing, before.Version)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if !saved {
+		result.Error = "Project has been modified by someone else"
+		return result
+	}
+
+	if req.Operation == bulkRemoveMember {
+		_, err = tx.Exec("DELETE FROM time_slots WHERE project_id = $1 AND role_key = $2 AND user_id = $3",
+			projectID, req.Role, req.UserID)
+		if err != nil {
+			result.Error = "Failed to delete time slots: " + err.Error()
+			return result
+		}
+	}
+
+	result.Success = true
+	result.Version = existing.Version
+	return result
+}
+
+// appendUnique 追加不存在的值，返回新切片
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(append([]string{}, values...), value)
+}
+
+// removeString 移除所有等于 value 的元素，返回新切片
+func removeString(values []string, value string) []string {
+	result := []string{}
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
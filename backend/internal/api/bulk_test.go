@@ -0,0 +1,100 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"project-management-backend/internal/models"
+)
+
+func TestBulkProjectRequestValidate(t *testing.T) {
+	many := make([]string, maxBulkProjects+1)
+	roleNames := map[string]string{"qaTesters": "测试", "designers": "设计"}
+	tests := []struct {
+		name    string
+		req     bulkProjectRequest
+		wantErr bool
+	}{
+		{"set status", bulkProjectRequest{ProjectIDs: []string{"p1"}, Operation: bulkSetStatus, Value: "已上线"}, false},
+		{"add member", bulkProjectRequest{ProjectIDs: []string{"p1"}, Operation: bulkAddMember, UserID: "u1", Role: "qaTesters"}, false},
+		{"remove follower", bulkProjectRequest{ProjectIDs: []string{"p1"}, Operation: bulkRemoveFollower, UserID: "u1"}, false},
+		{"no projects", bulkProjectRequest{Operation: bulkSetStatus, Value: "x"}, true},
+		{"too many projects", bulkProjectRequest{ProjectIDs: many, Operation: bulkSetStatus, Value: "x"}, true},
+		{"blank value", bulkProjectRequest{ProjectIDs: []string{"p1"}, Operation: bulkLinkKR, Value: " "}, true},
+		{"missing follower", bulkProjectRequest{ProjectIDs: []string{"p1"}, Operation: bulkAddFollower}, true},
+		{"custom role", bulkProjectRequest{ProjectIDs: []string{"p1"}, Operation: bulkAddMember, UserID: "u1", Role: "designers"}, false},
+		{"unknown role", bulkProjectRequest{ProjectIDs: []string{"p1"}, Operation: bulkAddMember, UserID: "u1", Role: "ops"}, true},
+		{"unknown operation", bulkProjectRequest{ProjectIDs: []string{"p1"}, Operation: "archive"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.validate(roleNames); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBulkProjectRequestApply(t *testing.T) {
+	base := func() models.Project {
+		return models.Project{
+			Status:       "开发中",
+			KeyResultIds: []string{"kr1"},
+			Followers:    []string{"u1", "u2"},
+			QaTesters:    models.Role{{UserID: "u1", TimeSlots: []models.TimeSlot{{StartDate: "2026-10-01"}}}},
+		}
+	}
+	tests := []struct {
+		name  string
+		req   bulkProjectRequest
+		check func(t *testing.T, p models.Project)
+	}{
+		{"set status", bulkProjectRequest{Operation: bulkSetStatus, Value: "已上线"}, func(t *testing.T, p models.Project) {
+			if p.Status != "已上线" {
+				t.Errorf("status = %s", p.Status)
+			}
+		}},
+		{"link existing kr", bulkProjectRequest{Operation: bulkLinkKR, Value: "kr1"}, func(t *testing.T, p models.Project) {
+			if !reflect.DeepEqual(p.KeyResultIds, []string{"kr1"}) {
+				t.Errorf("keyResultIds = %v", p.KeyResultIds)
+			}
+		}},
+		{"remove follower", bulkProjectRequest{Operation: bulkRemoveFollower, UserID: "u1"}, func(t *testing.T, p models.Project) {
+			if !reflect.DeepEqual(p.Followers, []string{"u2"}) {
+				t.Errorf("followers = %v", p.Followers)
+			}
+		}},
+		{"add existing member keeps slots", bulkProjectRequest{Operation: bulkAddMember, UserID: "u1", Role: "qaTesters"}, func(t *testing.T, p models.Project) {
+			if len(p.QaTesters) != 1 || len(p.QaTesters[0].TimeSlots) != 1 {
+				t.Errorf("qaTesters = %+v", p.QaTesters)
+			}
+		}},
+		{"add new member", bulkProjectRequest{Operation: bulkAddMember, UserID: "u2", Role: "qaTesters"}, func(t *testing.T, p models.Project) {
+			if len(p.QaTesters) != 2 || p.QaTesters[1].UserID != "u2" || p.QaTesters[1].TimeSlots == nil {
+				t.Errorf("qaTesters = %+v", p.QaTesters)
+			}
+		}},
+		{"remove member", bulkProjectRequest{Operation: bulkRemoveMember, UserID: "u1", Role: "qaTesters"}, func(t *testing.T, p models.Project) {
+			if len(p.QaTesters) != 0 {
+				t.Errorf("qaTesters = %+v", p.QaTesters)
+			}
+		}},
+		{"add custom role member", bulkProjectRequest{Operation: bulkAddMember, UserID: "u3", Role: "designers"}, func(t *testing.T, p models.Project) {
+			if len(p.Members["designers"]) != 1 || p.Members["designers"][0].UserID != "u3" {
+				t.Errorf("members = %+v", p.Members)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := base()
+			p := base()
+			tt.req.apply(&p)
+			tt.check(t, p)
+			// 操作不能修改原项目共享的切片，否则变更日志无法对比出差异
+			if !reflect.DeepEqual(original, base()) {
+				t.Error("apply mutated shared state")
+			}
+		})
+	}
+}
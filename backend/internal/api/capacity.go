@@ -0,0 +1,309 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCapacityReportDays 容量报表单次查询的最大天数
+const maxCapacityReportDays = 366
+
+// fullAllocation 一个工作日的满负荷（百分比）
+const fullAllocation = 100
+
+// capacityProject 用户在单个项目上的占用
+type capacityProject struct {
+	ProjectID   string   `json:"projectId"`
+	ProjectName string   `json:"projectName"`
+	Roles       []string `json:"roles"`
+	BookedDays  int      `json:"bookedDays"`
+	PersonDays  float64  `json:"personDays"`
+}
+
+// capacityOverlap 用户同时被多个项目占用的连续工作日
+type capacityOverlap struct {
+	StartDate  string   `json:"startDate"`
+	EndDate    string   `json:"endDate"`
+	Days       int      `json:"days"`
+	ProjectIDs []string `json:"projectIds"`
+	// Allocation 该时间段内单日最高的合计投入比例
+	Allocation int `json:"allocation"`
+}
+
+// capacityUser 单个用户在查询范围内的容量
+type capacityUser struct {
+	UserID            string             `json:"userId"`
+	Name              string             `json:"name"`
+	DeptID            *int               `json:"deptId"`
+	DeptName          string             `json:"deptName"`
+	BookedDays        int                `json:"bookedDays"`
+	PersonDays        float64            `json:"personDays"`
+	PeakAllocation    int                `json:"peakAllocation"`
+	OverAllocated     bool               `json:"overAllocated"`
+	OverAllocatedDays int                `json:"overAllocatedDays"`
+	Projects          []*capacityProject `json:"projects"`
+	Overlaps          []capacityOverlap  `json:"overlaps"`
+
+	// days 每个工作日（按查询范围内的下标）各项目的投入比例
+	days map[int]map[string]int
+}
+
+// capacityDepartment 按部门分组的容量
+type capacityDepartment struct {
+	DeptID             *int            `json:"deptId"`
+	DeptName           string          `json:"deptName"`
+	OverAllocatedUsers int             `json:"overAllocatedUsers"`
+	Users              []*capacityUser `json:"users"`
+}
+
+// workingDaysBetween 返回 [from, to] 范围内的工作日（周一至周五）
+func workingDaysBetween(from, to time.Time) []time.Time {
+	var days []time.Time
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// parseReportRange 解析报表的 from/to 参数
+func parseReportRange(c *gin.Context) (time.Time, time.Time, error) {
+	fromParam, toParam := c.Query("from"), c.Query("to")
+	if fromParam == "" || toParam == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("from and to are required")
+	}
+	from, err := time.Parse("2006-01-02", fromParam)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid from %q, expected YYYY-MM-DD", fromParam)
+	}
+	to, err := time.Parse("2006-01-02", toParam)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid to %q, expected YYYY-MM-DD", toParam)
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to must not be before from")
+	}
+	if to.Sub(from).Hours()/24 >= maxCapacityReportDays {
+		return time.Time{}, time.Time{}, fmt.Errorf("date range cannot exceed %d days", maxCapacityReportDays)
+	}
+	return from, to, nil
+}
+
+// GetCapacityReport 跨项目容量报表：统计查询范围内每个用户在各项目上占用的工作日，
+// 标记同时占用多个项目的时间段及超负荷情况；groupBy=dept 时按部门分组
+// 不包含已删除和已归档的项目
+func (h *Handler) GetCapacityReport(c *gin.Context) {
+	from, to, err := parseReportRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	groupBy := c.Query("groupBy")
+	if groupBy != "" && groupBy != "dept" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "groupBy must be dept"})
+		return
+	}
+
+	var deptFilter *int
+	if deptParam := c.Query("deptId"); deptParam != "" {
+		deptID, err := strconv.Atoi(deptParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid deptId"})
+			return
+		}
+		deptFilter = &deptID
+	}
+
+	workingDays := workingDaysBetween(from, to)
+	dayIndex := make(map[string]int, len(workingDays))
+	for i, day := range workingDays {
+		dayIndex[day.Format("2006-01-02")] = i
+	}
+
+	rows, err := h.db.Query(`
+		SELECT ts.user_id, COALESCE(u.name, ''), u.dept_id, COALESCE(u.dept_name, ''),
+			ts.project_id, p.name, ts.role_key, ts.start_date, ts.end_date, ts.allocation_percent
+		FROM time_slots ts
+		JOIN projects p ON p.id = ts.project_id
+		LEFT JOIN users u ON u.id = ts.user_id
+		WHERE p.deleted_at IS NULL AND p.archived_at IS NULL
+			AND ts.start_date IS NOT NULL AND ts.end_date IS NOT NULL
+			AND ts.start_date <= $2 AND ts.end_date >= $1
+		ORDER BY ts.user_id, p.name, ts.project_id`,
+		from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	users := make(map[string]*capacityUser)
+	var userOrder []string
+	projectsByUser := make(map[string]map[string]*capacityProject)
+
+	for rows.Next() {
+		var userID, name, deptName, projectID, projectName, roleKey string
+		var deptID *int
+		var startDate, endDate *string
+		var allocation int
+		if err := rows.Scan(&userID, &name, &deptID, &deptName, &projectID, &projectName, &roleKey, &startDate, &endDate, &allocation); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if deptFilter != nil && (deptID == nil || *deptID != *deptFilter) {
+			continue
+		}
+
+		user, ok := users[userID]
+		if !ok {
+			user = &capacityUser{
+				UserID:   userID,
+				Name:     name,
+				DeptID:   deptID,
+				DeptName: deptName,
+				Projects: []*capacityProject{},
+				Overlaps: []capacityOverlap{},
+				days:     make(map[int]map[string]int),
+			}
+			users[userID] = user
+			userOrder = append(userOrder, userID)
+			projectsByUser[userID] = make(map[string]*capacityProject)
+		}
+
+		project, ok := projectsByUser[userID][projectID]
+		if !ok {
+			project = &capacityProject{ProjectID: projectID, ProjectName: projectName, Roles: []string{}}
+			projectsByUser[userID][projectID] = project
+			user.Projects = append(user.Projects, project)
+		}
+		project.Roles = appendUnique(project.Roles, roleKey)
+
+		start, end := derefDate(startDate), derefDate(endDate)
+		for day, i := range dayIndex {
+			if day >= start && day <= end {
+				if user.days[i] == nil {
+					user.days[i] = make(map[string]int)
+				}
+				user.days[i][projectID] += allocation
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := make([]*capacityUser, 0, len(userOrder))
+	for _, userID := range userOrder {
+		user := users[userID]
+		summarizeCapacity(user, projectsByUser[userID], workingDays)
+		result = append(result, user)
+	}
+
+	response := gin.H{
+		"from":        from.Format("2006-01-02"),
+		"to":          to.Format("2006-01-02"),
+		"workingDays": len(workingDays),
+	}
+	if groupBy == "dept" {
+		response["departments"] = groupCapacityByDept(result)
+	} else {
+		response["users"] = result
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// summarizeCapacity 根据每日占用统计用户的占用天数、人天、峰值负荷与重叠时间段
+// 单日负荷为当天所有时段投入比例之和，超过 100% 视为超负荷
+func summarizeCapacity(user *capacityUser, projects map[string]*capacityProject, workingDays []time.Time) {
+	var current *capacityOverlap
+	for i, day := range workingDays {
+		booked := user.days[i]
+		allocation := 0
+		for projectID, percent := range booked {
+			projects[projectID].BookedDays++
+			projects[projectID].PersonDays += float64(percent) / fullAllocation
+			allocation += percent
+		}
+		if len(booked) > 0 {
+			user.BookedDays++
+		}
+		user.PersonDays += float64(allocation) / fullAllocation
+
+		if allocation > user.PeakAllocation {
+			user.PeakAllocation = allocation
+		}
+		if allocation > fullAllocation {
+			user.OverAllocatedDays++
+		}
+
+		// 合并项目组合相同的连续重叠工作日
+		if len(booked) < 2 {
+			current = nil
+			continue
+		}
+		projectIDs := sortedKeys(booked)
+		date := day.Format("2006-01-02")
+		if current != nil && strings.Join(current.ProjectIDs, ",") == strings.Join(projectIDs, ",") {
+			current.EndDate = date
+			current.Days++
+			if allocation > current.Allocation {
+				current.Allocation = allocation
+			}
+			continue
+		}
+		user.Overlaps = append(user.Overlaps, capacityOverlap{StartDate: date, EndDate: date, Days: 1, ProjectIDs: projectIDs, Allocation: allocation})
+		current = &user.Overlaps[len(user.Overlaps)-1]
+	}
+	user.OverAllocated = user.OverAllocatedDays > 0
+}
+
+// groupCapacityByDept 按部门分组，未设置部门的用户归入 deptId 为 null 的分组
+func groupCapacityByDept(users []*capacityUser) []*capacityDepartment {
+	departments := make(map[int]*capacityDepartment)
+	var noDept *capacityDepartment
+	var result []*capacityDepartment
+	for _, user := range users {
+		var dept *capacityDepartment
+		if user.DeptID == nil {
+			if noDept == nil {
+				noDept = &capacityDepartment{Users: []*capacityUser{}}
+			}
+			dept = noDept
+		} else if dept = departments[*user.DeptID]; dept == nil {
+			dept = &capacityDepartment{DeptID: user.DeptID, DeptName: user.DeptName, Users: []*capacityUser{}}
+			departments[*user.DeptID] = dept
+			result = append(result, dept)
+		}
+		dept.Users = append(dept.Users, user)
+		if user.OverAllocated {
+			dept.OverAllocatedUsers++
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return *result[i].DeptID < *result[j].DeptID })
+	if noDept != nil {
+		result = append(result, noDept)
+	}
+	if result == nil {
+		result = []*capacityDepartment{}
+	}
+	return result
+}
+
+// sortedKeys 返回 map 中排序后的键
+func sortedKeys(set map[string]int) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWorkingDaysBetween(t *testing.T) {
+	// 2026-10-12 为周一，范围内包含一个周末
+	from := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	var got []string
+	for _, day := range workingDaysBetween(from, to) {
+		got = append(got, day.Format("2006-01-02"))
+	}
+	want := []string{"2026-10-12", "2026-10-13", "2026-10-14", "2026-10-15", "2026-10-16", "2026-10-19"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("working days = %v, want %v", got, want)
+	}
+}
+
+func TestParseReportRange(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr bool
+	}{
+		{"from=2026-10-01&to=2026-10-31", false},
+		{"from=2026-10-01&to=2026-10-01", false},
+		{"from=2026-01-01&to=2027-01-01", false},
+		{"from=2026-01-01&to=2027-01-02", true},
+		{"from=2026-10-31&to=2026-10-01", true},
+		{"from=2026-10-01", true},
+		{"from=2026-10-01&to=10/31", true},
+	}
+	for _, tt := range tests {
+		_, _, err := parseReportRange(newQueryContext(tt.query))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.query, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSummarizeCapacity(t *testing.T) {
+	from := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	workingDays := workingDaysBetween(from, from.AddDate(0, 0, 4))
+	projects := map[string]*capacityProject{"a": {ProjectID: "a"}, "b": {ProjectID: "b"}}
+	user := &capacityUser{days: map[int]map[string]int{
+		0: {"a": 100},
+		1: {"a": 50, "b": 50},
+		2: {"a": 50, "b": 80},
+		4: {"a": 100, "b": 20},
+	}}
+
+	summarizeCapacity(user, projects, workingDays)
+
+	if user.BookedDays != 4 || projects["a"].BookedDays != 4 || projects["b"].BookedDays != 3 {
+		t.Errorf("booked days: user %d, a %d, b %d", user.BookedDays, projects["a"].BookedDays, projects["b"].BookedDays)
+	}
+	if user.PersonDays != 4.5 || projects["a"].PersonDays != 3 || projects["b"].PersonDays != 1.5 {
+		t.Errorf("person days: user %v, a %v, b %v", user.PersonDays, projects["a"].PersonDays, projects["b"].PersonDays)
+	}
+	// 周二两个项目各 50% 不算超负荷
+	if user.PeakAllocation != 130 || user.OverAllocatedDays != 2 || !user.OverAllocated {
+		t.Errorf("peak %d, over-allocated days %d", user.PeakAllocation, user.OverAllocatedDays)
+	}
+	// 周四空闲，重叠时间段被拆成两段，每段记录峰值负荷
+	want := []capacityOverlap{
+		{StartDate: "2026-10-13", EndDate: "2026-10-14", Days: 2, ProjectIDs: []string{"a", "b"}, Allocation: 130},
+		{StartDate: "2026-10-16", EndDate: "2026-10-16", Days: 1, ProjectIDs: []string{"a", "b"}, Allocation: 120},
+	}
+	if !reflect.DeepEqual(user.Overlaps, want) {
+		t.Errorf("overlaps = %+v, want %+v", user.Overlaps, want)
+	}
+}
+
+func TestGroupCapacityByDept(t *testing.T) {
+	dept := func(id int) *int { return &id }
+	users := []*capacityUser{
+		{UserID: "u1", DeptID: dept(2), OverAllocated: true},
+		{UserID: "u2"},
+		{UserID: "u3", DeptID: dept(1)},
+		{UserID: "u4", DeptID: dept(2)},
+	}
+	groups := groupCapacityByDept(users)
+	if len(groups) != 3 {
+		t.Fatalf("got %d groups, want 3", len(groups))
+	}
+	if *groups[0].DeptID != 1 || *groups[1].DeptID != 2 || groups[2].DeptID != nil {
+		t.Errorf("groups not ordered by dept with no-dept last")
+	}
+	if len(groups[1].Users) != 2 || groups[1].OverAllocatedUsers != 1 {
+		t.Errorf("dept 2 = %d users, %d over-allocated", len(groups[1].Users), groups[1].OverAllocatedUsers)
+	}
+	if got := groupCapacityByDept(nil); got == nil || len(got) != 0 {
+		t.Errorf("groupCapacityByDept(nil) = %#v, want empty slice", got)
+	}
+}
@@ -0,0 +1,317 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"project-management-backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// changeLogField 需要记录变更的项目字段
+// 角色字段设置 Role，比较时包含排期，展示时格式化为成员及排期
+type changeLogField struct {
+	Label  string
+	Format func(p *models.Project, names map[string]string) string
+	Role   func(p *models.Project) models.Role
+}
+
+// changeLogFields 按展示顺序排列的可记录字段，Label 与前端变更日志展示保持一致
+var changeLogFields = []changeLogField{
+	{Label: "项目名称", Format: func(p *models.Project, _ map[string]string) string { return p.Name }},
+	{Label: "优先级", Format: func(p *models.Project, _ map[string]string) string { return p.Priority }},
+	{Label: "状态", Format: func(p *models.Project, _ map[string]string) string { return p.Status }},
+	{Label: "健康度", Format: func(p *models.Project, _ map[string]string) string { return derefString(p.Health) }},
+	{Label: "健康度说明", Format: func(p *models.Project, _ map[string]string) string { return derefString(p.HealthReason) }},
+	{Label: "解决的业务问题", Format: func(p *models.Project, _ map[string]string) string { return derefString(p.BusinessProblem) }},
+	{Label: "本周进展/问题", Format: func(p *models.Project, _ map[string]string) string { return derefString(p.WeeklyUpdate) }},
+	{Label: "提出时间", Format: func(p *models.Project, _ map[string]string) string { return derefDate(p.ProposalDate) }},
+	{Label: "上线时间", Format: func(p *models.Project, _ map[string]string) string { return derefDate(p.LaunchDate) }},
+	{Label: "关联KR", Format: func(p *models.Project, _ map[string]string) string { return strings.Join(p.KeyResultIds, ", ") }},
+	{Label: "关注人", Format: func(p *models.Project, names map[string]string) string { return formatUserList(p.Followers, names) }},
+	{Label: "产品经理", Role: func(p *models.Project) models.Role { return p.ProductManagers }},
+	{Label: "后端研发", Role: func(p *models.Project) models.Role { return p.BackendDevelopers }},
+	{Label: "前端研发", Role: func(p *models.Project) models.Role { return p.FrontendDevelopers }},
+	{Label: "测试", Role: func(p *models.Project) models.Role { return p.QaTesters }},
+}
+
+// changeLogLabels 自定义角色与自定义字段在变更日志中的显示信息
+type changeLogLabels struct {
+	RoleNames    map[string]string
+	CustomFields map[string]models.CustomField
+}
+
+// buildChangeLog 对比更新前后的项目，生成服务端变更日志条目
+// 自定义角色与自定义字段使用 labels 中的名称作为字段名
+func buildChangeLog(before, after *models.Project, userID string, names map[string]string, labels changeLogLabels, now time.Time) []models.ChangeLogEntry {
+	var entries []models.ChangeLogEntry
+	add := func(label, oldValue, newValue string) {
+		entries = append(entries, models.ChangeLogEntry{
+			ID:        "cl_" + strconv.FormatInt(now.UnixNano(), 10) + "_" + strconv.Itoa(len(entries)),
+			UserID:    userID,
+			Field:     label,
+			OldValue:  oldValue,
+			NewValue:  newValue,
+			ChangedAt: now.Format(time.RFC3339),
+		})
+	}
+
+	for _, field := range changeLogFields {
+		if field.Role != nil {
+			oldRole, newRole := field.Role(before), field.Role(after)
+			if roleSignature(oldRole) != roleSignature(newRole) {
+				add(field.Label, formatRole(oldRole, names), formatRole(newRole, names))
+			}
+			continue
+		}
+		if oldValue, newValue := field.Format(before, names), field.Format(after, names); oldValue != newValue {
+			add(field.Label, oldValue, newValue)
+		}
+	}
+
+	for _, roleKey := range customRoleKeys(before, after) {
+		oldRole, newRole := roleMembers(before, roleKey), roleMembers(after, roleKey)
+		if roleSignature(oldRole) == roleSignature(newRole) {
+			continue
+		}
+		label := labels.RoleNames[roleKey]
+		if label == "" {
+			label = roleKey
+		}
+		add(label, formatRole(oldRole, names), formatRole(newRole, names))
+	}
+
+	for _, key := range changedCustomFieldKeys(before, after) {
+		field, ok := labels.CustomFields[key]
+		if !ok {
+			field = models.CustomField{Key: key, Name: key}
+		}
+		add(field.Name,
+			formatCustomFieldValue(field, before.CustomFields[key], names),
+			formatCustomFieldValue(field, after.CustomFields[key], names))
+	}
+	return entries
+}
+
+// changedCustomFieldKeys 返回前后值不同的自定义字段键（排序后）
+func changedCustomFieldKeys(before, after *models.Project) []string {
+	var keys []string
+	for key, value := range after.CustomFields {
+		if !reflect.DeepEqual(before.CustomFields[key], value) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range before.CustomFields {
+		if _, ok := after.CustomFields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatCustomFieldValue 格式化自定义字段的值，user 类型显示用户姓名
+func formatCustomFieldValue(field models.CustomField, value interface{}, names map[string]string) string {
+	switch v := value.(type) {
+	case nil:
+		return "无"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		if field.Type == "user" {
+			return displayName(v, names)
+		}
+		return v
+	}
+	return fmt.Sprint(value)
+}
+
+// customRoleKeys 返回项目中出现过的自定义角色键（排序后）
+func customRoleKeys(projects ...*models.Project) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, p := range projects {
+		for roleKey := range p.Members {
+			if !seen[roleKey] && builtInRole(p, roleKey) == nil {
+				seen[roleKey] = true
+				keys = append(keys, roleKey)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// recordProjectChanges 将 before 到 after 的变更追加到 after 的变更日志，并维护状态变更时间
+func (h *Handler) recordProjectChanges(before, after *models.Project, userID string, now time.Time) error {
+	var labels changeLogLabels
+	var err error
+	if len(customRoleKeys(before, after)) > 0 {
+		if labels.RoleNames, err = h.projectRoleNames(); err != nil {
+			return err
+		}
+	}
+
+	userIDs := projectUserIDs(before, after)
+	if changed := changedCustomFieldKeys(before, after); len(changed) > 0 {
+		if labels.CustomFields, err = h.customFieldsByKey(); err != nil {
+			return err
+		}
+		// user 类型的自定义字段同样显示用户姓名
+		for _, key := range changed {
+			if labels.CustomFields[key].Type != "user" {
+				continue
+			}
+			for _, p := range []*models.Project{before, after} {
+				if id, ok := p.CustomFields[key].(string); ok {
+					userIDs = appendUnique(userIDs, id)
+				}
+			}
+		}
+	}
+
+	userNames, err := h.lookupUserNames(userIDs)
+	if err != nil {
+		return err
+	}
+	if entries := buildChangeLog(before, after, userID, userNames, labels, now); len(entries) > 0 {
+		after.ChangeLog = append(entries, after.ChangeLog...)
+	}
+	if after.Status != before.Status {
+		statusChangedAt := now.Format(time.RFC3339)
+		after.StatusChangedAt = &statusChangedAt
+	}
+	return nil
+}
+
+// newCreationLogEntry 生成项目创建记录
+func newCreationLogEntry(project *models.Project, userID string, now time.Time) models.ChangeLogEntry {
+	return models.ChangeLogEntry{
+		ID:        "cl_" + strconv.FormatInt(now.UnixNano(), 10),
+		UserID:    userID,
+		Field:     "项目创建",
+		OldValue:  "",
+		NewValue:  project.Name,
+		ChangedAt: now.Format(time.RFC3339),
+	}
+}
+
+// roleSignature 序列化角色数据用于比较，空角色与 nil 视为相同
+func roleSignature(role models.Role) string {
+	if len(role) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(role)
+	return string(data)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func derefDate(s *string) string {
+	if s == nil {
+		return ""
+	}
+	if date, ok := normalizeDate(*s); ok {
+		return date
+	}
+	return *s
+}
+
+func displayName(userID string, names map[string]string) string {
+	if name, ok := names[userID]; ok && name != "" {
+		return name
+	}
+	return userID
+}
+
+func formatUserList(userIDs []string, names map[string]string) string {
+	parts := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		parts = append(parts, displayName(id, names))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatRole 格式化角色成员及其排期，如 "张三(2024-07-01~2024-08-15, 2024-09-01~2024-09-30 50%)"
+func formatRole(role models.Role, names map[string]string) string {
+	if len(role) == 0 {
+		return "无"
+	}
+	parts := make([]string, 0, len(role))
+	for _, member := range role {
+		name := displayName(member.UserID, names)
+		var ranges []string
+		for _, slot := range member.TimeSlots {
+			if slot.StartDate != "" || slot.EndDate != "" {
+				r := fmt.Sprintf("%s~%s", derefDate(&slot.StartDate), derefDate(&slot.EndDate))
+				if slot.AllocationPercent > 0 && slot.AllocationPercent < fullAllocation {
+					r += fmt.Sprintf(" %d%%", slot.AllocationPercent)
+				}
+				ranges = append(ranges, r)
+			}
+		}
+		if len(ranges) == 0 {
+			parts = append(parts, name+"(无排期)")
+		} else {
+			parts = append(parts, name+"("+strings.Join(ranges, ", ")+")")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// projectUserIDs 收集项目中出现的所有用户ID（团队成员与关注人）
+func projectUserIDs(projects ...*models.Project) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, p := range projects {
+		for _, roleKey := range projectRoleKeys(p) {
+			for _, member := range roleMembers(p, roleKey) {
+				add(member.UserID)
+			}
+		}
+		for _, id := range p.Followers {
+			add(id)
+		}
+	}
+	return ids
+}
+
+// lookupUserNames 批量查询用户姓名
+func (h *Handler) lookupUserNames(userIDs []string) (map[string]string, error) {
+	names := make(map[string]string)
+	if len(userIDs) == 0 {
+		return names, nil
+	}
+
+	rows, err := h.db.Query("SELECT id, name FROM users WHERE id = ANY($1)", pq.Array(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		names[id] = name
+	}
+	return names, rows.Err()
+}
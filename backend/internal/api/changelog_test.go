@@ -0,0 +1,119 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"project-management-backend/internal/models"
+)
+
+func TestBuildChangeLog(t *testing.T) {
+	s := func(v string) *string { return &v }
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	names := map[string]string{"u1": "张三", "u2": "李四"}
+	labels := changeLogLabels{
+		RoleNames:    map[string]string{"designers": "设计"},
+		CustomFields: map[string]models.CustomField{"owner": {Key: "owner", Name: "负责人", Type: "user"}},
+	}
+	base := func() models.Project {
+		return models.Project{
+			Name:       "项目",
+			Priority:   "P1",
+			Status:     "开发中",
+			LaunchDate: s("2026-11-01"),
+			Followers:  []string{"u1"},
+			BackendDevelopers: models.Role{{UserID: "u1", TimeSlots: []models.TimeSlot{
+				{StartDate: "2026-10-01", EndDate: "2026-10-31"},
+			}}},
+		}
+	}
+	type change struct{ Field, Old, New string }
+	tests := []struct {
+		name   string
+		mutate func(p *models.Project)
+		want   []change
+	}{
+		{name: "no changes", mutate: func(p *models.Project) {}},
+		{name: "same date in another format", mutate: func(p *models.Project) { p.LaunchDate = s("2026-11-01T00:00:00Z") }},
+		{name: "empty role equals nil role", mutate: func(p *models.Project) { p.QaTesters = models.Role{} }},
+		{name: "scalar fields in display order", mutate: func(p *models.Project) {
+			p.Status = "已上线"
+			p.Name = "新项目"
+		}, want: []change{{"项目名称", "项目", "新项目"}, {"状态", "开发中", "已上线"}}},
+		{name: "cleared date", mutate: func(p *models.Project) { p.LaunchDate = nil },
+			want: []change{{"上线时间", "2026-11-01", ""}}},
+		{name: "followers use display names", mutate: func(p *models.Project) { p.Followers = []string{"u1", "u2", "u3"} },
+			want: []change{{"关注人", "张三", "张三, 李四, u3"}}},
+		{name: "role schedule change", mutate: func(p *models.Project) {
+			p.BackendDevelopers[0].TimeSlots[0].EndDate = "2026-11-15"
+		}, want: []change{{"后端研发", "张三(2026-10-01~2026-10-31)", "张三(2026-10-01~2026-11-15)"}}},
+		{name: "member added without schedule", mutate: func(p *models.Project) {
+			p.QaTesters = models.Role{{UserID: "u2"}}
+		}, want: []change{{"测试", "无", "李四(无排期)"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, after := base(), base()
+			tt.mutate(&after)
+			entries := buildChangeLog(&before, &after, "u9", names, labels, now)
+
+			var got []change
+			for _, e := range entries {
+				got = append(got, change{e.Field, e.OldValue, e.NewValue})
+				if e.UserID != "u9" || e.ChangedAt != "2026-10-17T09:00:00Z" {
+					t.Errorf("entry metadata = %+v", e)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildChangeLogUniqueIDs(t *testing.T) {
+	before := models.Project{Name: "a", Priority: "P1", Status: "开发中"}
+	after := models.Project{Name: "b", Priority: "P0", Status: "已上线"}
+	entries := buildChangeLog(&before, &after, "u1", nil, changeLogLabels{}, time.Now())
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if seen[e.ID] {
+			t.Fatalf("duplicate change log id %s", e.ID)
+		}
+		seen[e.ID] = true
+	}
+	if len(entries) != 3 {
+		t.Errorf("got %d entries, want 3", len(entries))
+	}
+}
+
+func TestRecordProjectChanges(t *testing.T) {
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	existing := []models.ChangeLogEntry{{ID: "old"}}
+	tests := []struct {
+		name          string
+		status        string
+		wantEntries   int
+		wantChangedAt bool
+	}{
+		{"status changed", "已上线", 2, true},
+		{"nothing changed", "开发中", 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := models.Project{Name: "a", Priority: "P1", Status: "开发中", ChangeLog: existing}
+			after := before
+			after.Status = tt.status
+			if err := (&Handler{}).recordProjectChanges(&before, &after, "u1", now); err != nil {
+				t.Fatal(err)
+			}
+			if len(after.ChangeLog) != tt.wantEntries || after.ChangeLog[len(after.ChangeLog)-1].ID != "old" {
+				t.Errorf("change log = %+v", after.ChangeLog)
+			}
+			if (after.StatusChangedAt != nil) != tt.wantChangedAt {
+				t.Errorf("statusChangedAt = %v, want set %v", after.StatusChangedAt, tt.wantChangedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"project-management-backend/internal/middleware"
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cloneProjectRequest 克隆项目时可选择复制的内容
+type cloneProjectRequest struct {
+	Name                   string `json:"name"`
+	IncludeRoles           bool   `json:"includeRoles"`
+	IncludeTimeSlots       bool   `json:"includeTimeSlots"`
+	IncludeKeyResults      bool   `json:"includeKeyResults"`
+	IncludeFollowers       bool   `json:"includeFollowers"`
+	IncludeBusinessProblem bool   `json:"includeBusinessProblem"`
+}
+
+// CloneProject 基于现有项目创建新项目（如新一期迭代）
+// 评论、变更日志、周进展及日期不会复制，状态重置为"未开始"
+func (h *Handler) CloneProject(c *gin.Context) {
+	projectID := c.Param("projectId")
+
+	// 未指定的选项默认复制（时段除外）
+	req := cloneProjectRequest{
+		IncludeRoles:           true,
+		IncludeKeyResults:      true,
+		IncludeFollowers:       true,
+		IncludeBusinessProblem: true,
+	}
+	// 请求体为空（包括分块传输的空请求体）时使用默认选项
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.IncludeTimeSlots && !req.IncludeRoles {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "includeTimeSlots requires includeRoles"})
+		return
+	}
+
+	source, err := h.getProjectByID(projectID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	// 副本从状态流转的初始状态开始
+	workflow, err := h.loadWorkflow()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workflow: " + err.Error()})
+		return
+	}
+
+	now := time.Now()
+	project := models.Project{
+		ID:                 "p" + strconv.FormatInt(now.UnixNano(), 10),
+		Name:               strings.TrimSpace(req.Name),
+		Priority:           source.Priority,
+		Status:             workflow.InitialStatus,
+		KeyResultIds:       []string{},
+		Followers:          []string{},
+		ProductManagers:    models.Role{},
+		BackendDevelopers:  models.Role{},
+		FrontendDevelopers: models.Role{},
+		QaTesters:          models.Role{},
+		Members:            map[string]models.Role{},
+		CustomFields:       map[string]interface{}{},
+		CreatedAt:          now.Format(time.RFC3339),
+		Comments:           []models.Comment{},
+		Version:            1,
+	}
+	project.StatusChangedAt = &project.CreatedAt
+	if project.Name == "" {
+		project.Name = source.Name + "（副本）"
+	}
+
+	for key, value := range source.CustomFields {
+		project.CustomFields[key] = value
+	}
+	if req.IncludeBusinessProblem && source.BusinessProblem != nil {
+		businessProblem := *source.BusinessProblem
+		project.BusinessProblem = &businessProblem
+	}
+	if req.IncludeKeyResults {
+		project.KeyResultIds = append(project.KeyResultIds, source.KeyResultIds...)
+	}
+	if req.IncludeFollowers {
+		project.Followers = append(project.Followers, source.Followers...)
+	}
+	if req.IncludeRoles {
+		project.ProductManagers = cloneRole(source.ProductManagers, req.IncludeTimeSlots)
+		project.BackendDevelopers = cloneRole(source.BackendDevelopers, req.IncludeTimeSlots)
+		project.FrontendDevelopers = cloneRole(source.FrontendDevelopers, req.IncludeTimeSlots)
+		project.QaTesters = cloneRole(source.QaTesters, req.IncludeTimeSlots)
+		for roleKey, role := range source.Members {
+			project.Members[roleKey] = cloneRole(role, req.IncludeTimeSlots)
+		}
+	}
+
+	userID, _, _, _ := middleware.GetCurrentUser(c)
+	project.ChangeLog = []models.ChangeLogEntry{newCreationLogEntry(&project, userID, now)}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := insertProject(tx, &project); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := insertTimeSlots(tx, &project, nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save time slots: " + err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.Header("ETag", projectETag(project.Version))
+	c.JSON(http.StatusCreated, project)
+}
+
+// cloneRole 复制角色成员，可选择是否保留时段
+func cloneRole(role models.Role, includeTimeSlots bool) models.Role {
+	cloned := make(models.Role, 0, len(role))
+	for _, member := range role {
+		m := models.TeamMember{
+			UserID:            member.UserID,
+			TimeSlots:         []models.TimeSlot{},
+			UseSharedSchedule: member.UseSharedSchedule,
+		}
+		if includeTimeSlots {
+			m.TimeSlots = append(m.TimeSlots, member.TimeSlots...)
+			m.StartDate = member.StartDate
+			m.EndDate = member.EndDate
+		}
+		cloned = append(cloned, m)
+	}
+	return cloned
+}
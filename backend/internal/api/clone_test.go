@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCloneRole(t *testing.T) {
+	start := "2026-10-01"
+	role := models.Role{{
+		UserID:            "u1",
+		UseSharedSchedule: true,
+		StartDate:         &start,
+		TimeSlots:         []models.TimeSlot{{StartDate: "2026-10-01", EndDate: "2026-10-31"}},
+	}}
+
+	withoutSlots := cloneRole(role, false)
+	want := models.Role{{UserID: "u1", UseSharedSchedule: true, TimeSlots: []models.TimeSlot{}}}
+	if !reflect.DeepEqual(withoutSlots, want) {
+		t.Errorf("cloneRole without slots = %+v, want %+v", withoutSlots, want)
+	}
+
+	withSlots := cloneRole(role, true)
+	if !reflect.DeepEqual(withSlots, role) {
+		t.Errorf("cloneRole with slots = %+v, want %+v", withSlots, role)
+	}
+	withSlots[0].TimeSlots[0].EndDate = "2026-12-31"
+	if role[0].TimeSlots[0].EndDate != "2026-10-31" {
+		t.Error("cloned time slots share storage with the source")
+	}
+
+	if got := cloneRole(nil, true); got == nil || len(got) != 0 {
+		t.Errorf("cloneRole(nil) = %#v, want empty role", got)
+	}
+}
+
+// performClone 以指定请求体调用 CloneProject，body 为 nil 时发送空的分块请求体
+func performClone(h *Handler, projectID string, body *string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	var req *http.Request
+	if body == nil {
+		req = httptest.NewRequest("POST", "/api/projects/"+projectID+"/clone", http.NoBody)
+		req.ContentLength = -1
+	} else {
+		req = httptest.NewRequest("POST", "/api/projects/"+projectID+"/clone", strings.NewReader(*body))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.Request = req
+	c.Params = gin.Params{{Key: "projectId", Value: projectID}}
+	h.CloneProject(c)
+	return w
+}
+
+func TestCloneProjectRejectsInvalidOptions(t *testing.T) {
+	for _, body := range []string{`{"includeTimeSlots":true,"includeRoles":false}`, `{"name":`} {
+		w := performClone(&Handler{}, "p1", &body)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("body %s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
+
+func TestCloneProject(t *testing.T) {
+	db := openTestDB(t)
+	h := &Handler{db: db}
+	sourceID := "test-clone-source"
+	insertTestProject(t, db, sourceID, "源项目", "P0", "开发中")
+
+	// 空请求体使用默认选项
+	w := performClone(h, sourceID, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var clone models.Project
+	if err := json.Unmarshal(w.Body.Bytes(), &clone); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Exec("DELETE FROM projects WHERE id = $1", clone.ID) })
+
+	if clone.ID == sourceID || clone.Name != "源项目（副本）" || clone.Priority != "P0" || clone.Status != "未开始" {
+		t.Errorf("clone = %s %q %s %s", clone.ID, clone.Name, clone.Priority, clone.Status)
+	}
+	if len(clone.ChangeLog) != 1 {
+		t.Errorf("clone change log has %d entries, want 1", len(clone.ChangeLog))
+	}
+
+	if w := performClone(h, "test-clone-missing", nil); w.Code != http.StatusNotFound {
+		t.Errorf("missing source status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,400 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// customFieldKeyPattern 自定义字段键格式，与角色键保持一致的驼峰风格
+var customFieldKeyPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,49}$`)
+
+// isCustomFieldType 判断是否为支持的自定义字段类型
+func isCustomFieldType(fieldType string) bool {
+	for _, t := range models.CustomFieldTypes {
+		if t == fieldType {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeCustomFieldOptions 校验 select 类型的可选值（非空且不重复），其他类型不允许设置可选值
+func normalizeCustomFieldOptions(fieldType string, options []string) ([]string, error) {
+	if fieldType != "select" {
+		if len(options) > 0 {
+			return nil, fmt.Errorf("options are only allowed for select fields")
+		}
+		return nil, nil
+	}
+	var normalized []string
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return nil, fmt.Errorf("options must not contain empty values")
+		}
+		for _, existing := range normalized {
+			if existing == option {
+				return nil, fmt.Errorf("duplicate option %q", option)
+			}
+		}
+		normalized = append(normalized, option)
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("select fields require at least one option")
+	}
+	return normalized, nil
+}
+
+// scanCustomField 扫描一行自定义字段定义
+func scanCustomField(row rowScanner) (models.CustomField, error) {
+	var field models.CustomField
+	var options []byte
+	if err := row.Scan(&field.Key, &field.Name, &field.Type, &options, &field.SortOrder); err != nil {
+		return field, err
+	}
+	json.Unmarshal(options, &field.Options)
+	return field, nil
+}
+
+// loadCustomFields 读取所有自定义字段定义
+func (h *Handler) loadCustomFields() ([]models.CustomField, error) {
+	rows, err := h.db.Query("SELECT field_key, name, field_type, options, sort_order FROM custom_fields ORDER BY sort_order, field_key")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := []models.CustomField{}
+	for rows.Next() {
+		field, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, rows.Err()
+}
+
+// customFieldsByKey 返回字段键到定义的映射
+func (h *Handler) customFieldsByKey() (map[string]models.CustomField, error) {
+	fields, err := h.loadCustomFields()
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]models.CustomField, len(fields))
+	for _, field := range fields {
+		byKey[field.Key] = field
+	}
+	return byKey, nil
+}
+
+// normalizeCustomFieldValue 按字段类型校验并规范化单个值
+func normalizeCustomFieldValue(field models.CustomField, value interface{}) (interface{}, error) {
+	if field.Type == "number" {
+		number, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return number, nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("must be a string")
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("cannot be empty, use null to clear")
+	}
+	switch field.Type {
+	case "date":
+		date, ok := normalizeDate(text)
+		if !ok {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", text)
+		}
+		return date, nil
+	case "select":
+		for _, option := range field.Options {
+			if option == text {
+				return text, nil
+			}
+		}
+		return nil, fmt.Errorf("must be one of %s", strings.Join(field.Options, ", "))
+	}
+	return text, nil
+}
+
+// checkCustomFieldValues 校验相对 before 有变化的自定义字段值（before 为 nil 时校验全部），
+// 值会被规范化，返回以 customFields.<key> 为键的逐字段错误
+func (h *Handler) checkCustomFieldValues(p, before *models.Project) (map[string]string, error) {
+	var changed []string
+	for key, value := range p.CustomFields {
+		if before == nil || !reflect.DeepEqual(before.CustomFields[key], value) {
+			changed = append(changed, key)
+		}
+	}
+	fieldErrors := make(map[string]string)
+	if len(changed) == 0 {
+		return fieldErrors, nil
+	}
+
+	fields, err := h.customFieldsByKey()
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := make(map[string]interface{}, len(p.CustomFields))
+	for key, value := range p.CustomFields {
+		normalized[key] = value
+	}
+	var userIDs []string
+	for _, key := range changed {
+		if p.CustomFields[key] == nil {
+			delete(normalized, key)
+			continue
+		}
+		field, ok := fields[key]
+		if !ok {
+			fieldErrors["customFields."+key] = "undefined custom field"
+			continue
+		}
+		value, err := normalizeCustomFieldValue(field, p.CustomFields[key])
+		if err != nil {
+			fieldErrors["customFields."+key] = err.Error()
+			continue
+		}
+		normalized[key] = value
+		if field.Type == "user" {
+			userIDs = append(userIDs, value.(string))
+		}
+	}
+
+	// user 类型的值需为已存在的用户
+	if len(userIDs) > 0 {
+		names, err := h.lookupUserNames(userIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range changed {
+			userID, _ := normalized[key].(string)
+			if fields[key].Type != "user" || userID == "" || fieldErrors["customFields."+key] != "" {
+				continue
+			}
+			if _, ok := names[userID]; !ok {
+				fieldErrors["customFields."+key] = "user not found"
+			}
+		}
+	}
+
+	p.CustomFields = normalized
+	return fieldErrors, nil
+}
+
+// customFieldValuesInUse 返回仍被项目使用的可选值
+func (h *Handler) customFieldValuesInUse(key string, values []string) ([]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	rows, err := h.db.Query(
+		"SELECT DISTINCT custom_fields ->> $1 FROM projects WHERE custom_fields ->> $1 = ANY($2)",
+		key, pq.Array(values))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var inUse []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		inUse = append(inUse, value)
+	}
+	sort.Strings(inUse)
+	return inUse, rows.Err()
+}
+
+// GetCustomFields 获取所有自定义字段定义
+func (h *Handler) GetCustomFields(c *gin.Context) {
+	fields, err := h.loadCustomFields()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, fields)
+}
+
+// CreateCustomField 新增自定义字段（管理员）
+func (h *Handler) CreateCustomField(c *gin.Context) {
+	var field models.CustomField
+	if err := c.ShouldBindJSON(&field); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	field.Name = strings.TrimSpace(field.Name)
+	if !customFieldKeyPattern.MatchString(field.Key) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "key must start with a letter and contain only letters, digits or underscores"})
+		return
+	}
+	if field.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	if !isCustomFieldType(field.Type) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of " + strings.Join(models.CustomFieldTypes, ", ")})
+		return
+	}
+	options, err := normalizeCustomFieldOptions(field.Type, field.Options)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	field.Options = options
+	optionsJSON, _ := json.Marshal(append([]string{}, options...))
+
+	result, err := h.db.Exec(`
+		INSERT INTO custom_fields (field_key, name, field_type, options, sort_order)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (field_key) DO NOTHING`,
+		field.Key, field.Name, field.Type, optionsJSON, field.SortOrder)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Custom field already exists"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, field)
+}
+
+// UpdateCustomField 修改自定义字段的名称、可选值或排序（管理员），字段键与类型不可修改
+// 仍被项目使用的可选值不能移除
+func (h *Handler) UpdateCustomField(c *gin.Context) {
+	fieldKey := c.Param("fieldKey")
+
+	var req struct {
+		Name      *string  `json:"name"`
+		Type      *string  `json:"type"`
+		Options   []string `json:"options"`
+		SortOrder *int     `json:"sortOrder"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	field, err := scanCustomField(h.db.QueryRow(
+		"SELECT field_key, name, field_type, options, sort_order FROM custom_fields WHERE field_key = $1", fieldKey))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Custom field not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if req.Type != nil && *req.Type != field.Type {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type cannot be changed"})
+		return
+	}
+	if req.Name != nil {
+		field.Name = strings.TrimSpace(*req.Name)
+		if field.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name cannot be empty"})
+			return
+		}
+	}
+	if req.SortOrder != nil {
+		field.SortOrder = *req.SortOrder
+	}
+	if req.Options != nil {
+		options, err := normalizeCustomFieldOptions(field.Type, req.Options)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var removed []string
+		for _, old := range field.Options {
+			kept := false
+			for _, option := range options {
+				kept = kept || option == old
+			}
+			if !kept {
+				removed = append(removed, old)
+			}
+		}
+		inUse, err := h.customFieldValuesInUse(fieldKey, removed)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if len(inUse) > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Options still in use: " + strings.Join(inUse, ", ")})
+			return
+		}
+		field.Options = options
+	}
+
+	optionsJSON, _ := json.Marshal(append([]string{}, field.Options...))
+	_, err = h.db.Exec("UPDATE custom_fields SET name = $2, options = $3, sort_order = $4 WHERE field_key = $1",
+		fieldKey, field.Name, optionsJSON, field.SortOrder)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, field)
+}
+
+// DeleteCustomField 删除自定义字段（管理员），同时清除所有项目上该字段的值
+func (h *Handler) DeleteCustomField(c *gin.Context) {
+	fieldKey := c.Param("fieldKey")
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM custom_fields WHERE field_key = $1", fieldKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Custom field not found"})
+		return
+	}
+
+	// 清除项目上的值并递增版本号，使持有旧数据的整体更新失效
+	_, err = tx.Exec(`
+		UPDATE projects
+		SET custom_fields = custom_fields - $1, version = version + 1
+		WHERE custom_fields -> $1 IS NOT NULL`, fieldKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
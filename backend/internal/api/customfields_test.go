@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"project-management-backend/internal/models"
+)
+
+func TestNormalizeCustomFieldOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldType string
+		options   []string
+		want      []string
+		wantErr   bool
+	}{
+		{"select trims options", "select", []string{" 高 ", "低"}, []string{"高", "低"}, false},
+		{"select requires options", "select", nil, nil, true},
+		{"select rejects empty option", "select", []string{"高", " "}, nil, true},
+		{"select rejects duplicates", "select", []string{"高", " 高"}, nil, true},
+		{"text without options", "text", nil, nil, false},
+		{"text with options", "text", []string{"a"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeCustomFieldOptions(tt.fieldType, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("options = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeCustomFieldValue(t *testing.T) {
+	selectField := models.CustomField{Type: "select", Options: []string{"高", "低"}}
+	tests := []struct {
+		name    string
+		field   models.CustomField
+		value   interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{"number", models.CustomField{Type: "number"}, 3.5, 3.5, false},
+		{"number as string", models.CustomField{Type: "number"}, "3", nil, true},
+		{"text trimmed", models.CustomField{Type: "text"}, "  备注 ", "备注", false},
+		{"blank text", models.CustomField{Type: "text"}, " ", nil, true},
+		{"date normalized", models.CustomField{Type: "date"}, "2026-10-17T08:00:00Z", "2026-10-17", false},
+		{"invalid date", models.CustomField{Type: "date"}, "17/10/2026", nil, true},
+		{"select option", selectField, "高", "高", false},
+		{"unknown select option", selectField, "中", nil, true},
+		{"user id", models.CustomField{Type: "user"}, "u1", "u1", false},
+		{"text as number", models.CustomField{Type: "text"}, 1.0, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeCustomFieldValue(tt.field, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("value = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangedCustomFieldKeys(t *testing.T) {
+	before := &models.Project{CustomFields: map[string]interface{}{"a": "x", "b": 1.0, "c": "same"}}
+	after := &models.Project{CustomFields: map[string]interface{}{"a": "y", "c": "same", "d": "new"}}
+	if got, want := changedCustomFieldKeys(before, after), []string{"a", "b", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("changed keys = %v, want %v", got, want)
+	}
+}
+
+func TestPatchCustomFields(t *testing.T) {
+	p := models.Project{CustomFields: map[string]interface{}{"a": "x", "b": "y"}}
+	shared := p.CustomFields
+	patch := map[string]json.RawMessage{"customFields": json.RawMessage(`{"a":null,"c":2}`)}
+	if _, fieldErrors := applyProjectPatch(&p, patch); len(fieldErrors) > 0 {
+		t.Fatalf("unexpected errors %v", fieldErrors)
+	}
+	// 未出现的字段保持不变，null 清空字段
+	if want := map[string]interface{}{"b": "y", "c": 2.0}; !reflect.DeepEqual(p.CustomFields, want) {
+		t.Errorf("customFields = %v, want %v", p.CustomFields, want)
+	}
+	if _, ok := shared["a"]; !ok {
+		t.Error("patch modified the original customFields map")
+	}
+
+	patch = map[string]json.RawMessage{"customFields": json.RawMessage(`null`)}
+	applyProjectPatch(&p, patch)
+	if len(p.CustomFields) != 0 {
+		t.Errorf("customFields = %v, want empty", p.CustomFields)
+	}
+}
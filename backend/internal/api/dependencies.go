@@ -0,0 +1,361 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"project-management-backend/internal/middleware"
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 依赖方向（相对当前项目）
+const (
+	dependencyBlocks    = "blocks"
+	dependencyBlockedBy = "blockedBy"
+)
+
+// dependentLaunchesBeforeBlocker 被阻塞项目的上线时间早于阻塞它的项目
+const dependentLaunchesBeforeBlocker = "DEPENDENT_LAUNCHES_BEFORE_BLOCKER"
+
+// loadProjectDependencies 读取项目的依赖关系（两个方向），忽略已删除的项目
+func loadProjectDependencies(q queryer, projectID string) ([]models.ProjectDependency, error) {
+	rows, err := q.Query(`
+		SELECT d.id, 'blocks', p.id, p.name, p.status, p.launch_date, d.created_at, COALESCE(d.created_by, '')
+		FROM project_dependencies d
+		JOIN projects p ON p.id = d.blocked_id
+		WHERE d.blocker_id = $1 AND p.deleted_at IS NULL
+		UNION ALL
+		SELECT d.id, 'blockedBy', p.id, p.name, p.status, p.launch_date, d.created_at, COALESCE(d.created_by, '')
+		FROM project_dependencies d
+		JOIN projects p ON p.id = d.blocker_id
+		WHERE d.blocked_id = $1 AND p.deleted_at IS NULL
+		ORDER BY 2, 4`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dependencies := []models.ProjectDependency{}
+	for rows.Next() {
+		var d models.ProjectDependency
+		if err := rows.Scan(&d.ID, &d.Type, &d.ProjectID, &d.ProjectName, &d.Status, &d.LaunchDate, &d.CreatedAt, &d.CreatedBy); err != nil {
+			return nil, err
+		}
+		if d.LaunchDate != nil {
+			date := derefDate(d.LaunchDate)
+			d.LaunchDate = &date
+		}
+		dependencies = append(dependencies, d)
+	}
+	return dependencies, rows.Err()
+}
+
+// dependencyWarnings 检查被阻塞项目的上线时间是否早于阻塞项目，launchDate 为当前项目的上线时间
+func dependencyWarnings(projectID, launchDate string, dependencies []models.ProjectDependency) []models.DependencyWarning {
+	var warnings []models.DependencyWarning
+	for _, d := range dependencies {
+		otherLaunch := derefDate(d.LaunchDate)
+		if launchDate == "" || otherLaunch == "" {
+			continue
+		}
+		blockerID, blockerLaunch, blockedID, blockedLaunch := projectID, launchDate, d.ProjectID, otherLaunch
+		if d.Type == dependencyBlockedBy {
+			blockerID, blockerLaunch, blockedID, blockedLaunch = d.ProjectID, otherLaunch, projectID, launchDate
+		}
+		if blockedLaunch < blockerLaunch {
+			warnings = append(warnings, models.DependencyWarning{
+				Code:      dependentLaunchesBeforeBlocker,
+				BlockerID: blockerID,
+				BlockedID: blockedID,
+				Message:   fmt.Sprintf("project %s launches on %s, before its blocker %s launches on %s", blockedID, blockedLaunch, blockerID, blockerLaunch),
+			})
+		}
+	}
+	return warnings
+}
+
+// projectDependencyWarnings 读取项目的依赖关系并返回上线时间警告
+func projectDependencyWarnings(q queryer, project *models.Project) ([]models.DependencyWarning, error) {
+	dependencies, err := loadProjectDependencies(q, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	return dependencyWarnings(project.ID, derefDate(project.LaunchDate), dependencies), nil
+}
+
+// dependencyPath 在阻塞关系图中查找 from 到 to 的路径，不存在时返回 nil
+func dependencyPath(edges map[string][]string, from, to string) []string {
+	previous := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == to {
+			var path []string
+			for node := to; node != ""; node = previous[node] {
+				path = append([]string{node}, path...)
+			}
+			return path
+		}
+		for _, next := range edges[current] {
+			if _, seen := previous[next]; !seen {
+				previous[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// loadDependencyEdges 读取两端项目都未删除的阻塞关系，返回 blocker -> blocked 的邻接表
+// 回收站中的项目不参与成环，恢复项目时需重新检查（见 findProjectDependencyCycle）
+func loadDependencyEdges(q queryer) (map[string][]string, error) {
+	rows, err := q.Query(`
+		SELECT d.blocker_id, d.blocked_id
+		FROM project_dependencies d
+		JOIN projects blocker ON blocker.id = d.blocker_id AND blocker.deleted_at IS NULL
+		JOIN projects blocked ON blocked.id = d.blocked_id AND blocked.deleted_at IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := make(map[string][]string)
+	for rows.Next() {
+		var blocker, blocked string
+		if err := rows.Scan(&blocker, &blocked); err != nil {
+			return nil, err
+		}
+		edges[blocker] = append(edges[blocker], blocked)
+	}
+	return edges, rows.Err()
+}
+
+// newDependencyCycle 检查新增 blocker -> blocked 的阻塞关系是否会形成环，返回环上的项目ID
+func newDependencyCycle(edges map[string][]string, blockerID, blockedID string) []string {
+	// 被阻塞项目已能（直接或间接）阻塞 blocker 时，新关系会形成环
+	path := dependencyPath(edges, blockedID, blockerID)
+	if path == nil {
+		return nil
+	}
+	return append(path, blockedID)
+}
+
+// dependencyCycleThrough 返回经过指定项目的环，不存在时返回 nil
+func dependencyCycleThrough(edges map[string][]string, projectID string) []string {
+	for _, next := range edges[projectID] {
+		if path := dependencyPath(edges, next, projectID); path != nil {
+			return append([]string{projectID}, path...)
+		}
+	}
+	return nil
+}
+
+// findDependencyCycle 检查新增 blocker -> blocked 的阻塞关系是否会形成环，返回环上的项目ID
+func findDependencyCycle(q queryer, blockerID, blockedID string) ([]string, error) {
+	edges, err := loadDependencyEdges(q)
+	if err != nil {
+		return nil, err
+	}
+	return newDependencyCycle(edges, blockerID, blockedID), nil
+}
+
+// findProjectDependencyCycle 检查项目现有的依赖关系是否成环，用于从回收站恢复项目：
+// 项目在回收站期间其他项目可能新增了与其依赖关系共同成环的关系
+func findProjectDependencyCycle(q queryer, projectID string) ([]string, error) {
+	edges, err := loadDependencyEdges(q)
+	if err != nil {
+		return nil, err
+	}
+	return dependencyCycleThrough(edges, projectID), nil
+}
+
+// GetProjectDependencies 获取项目的依赖关系及上线时间警告
+func (h *Handler) GetProjectDependencies(c *gin.Context) {
+	projectID := c.Param("projectId")
+
+	var launchDate *string
+	err := h.db.QueryRow("SELECT launch_date FROM projects WHERE id = $1 AND deleted_at IS NULL", projectID).Scan(&launchDate)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	dependencies, err := loadProjectDependencies(h.db, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	blocks, blockedBy := []models.ProjectDependency{}, []models.ProjectDependency{}
+	for _, d := range dependencies {
+		if d.Type == dependencyBlocks {
+			blocks = append(blocks, d)
+		} else {
+			blockedBy = append(blockedBy, d)
+		}
+	}
+
+	warnings := dependencyWarnings(projectID, derefDate(launchDate), dependencies)
+	if warnings == nil {
+		warnings = []models.DependencyWarning{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"blocks":    blocks,
+		"blockedBy": blockedBy,
+		"warnings":  warnings,
+	})
+}
+
+// CreateProjectDependency 新增依赖关系，type 为 blocks（当前项目阻塞对方）或 blockedBy（当前项目被对方阻塞）
+// 会形成循环依赖时拒绝
+func (h *Handler) CreateProjectDependency(c *gin.Context) {
+	projectID := c.Param("projectId")
+
+	var req struct {
+		Type      string `json:"type"`
+		ProjectID string `json:"projectId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.ProjectID = strings.TrimSpace(req.ProjectID)
+	if req.Type != dependencyBlocks && req.Type != dependencyBlockedBy {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be blocks or blockedBy"})
+		return
+	}
+	if req.ProjectID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "projectId is required"})
+		return
+	}
+	if req.ProjectID == projectID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A project cannot depend on itself"})
+		return
+	}
+
+	blockerID, blockedID := projectID, req.ProjectID
+	if req.Type == dependencyBlockedBy {
+		blockerID, blockedID = req.ProjectID, projectID
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	// 串行化依赖关系的写入，避免并发新增的两条关系共同形成环
+	if _, err := tx.Exec("LOCK TABLE project_dependencies IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var launchDate *string
+	err = tx.QueryRow("SELECT launch_date FROM projects WHERE id = $1 AND deleted_at IS NULL", projectID).Scan(&launchDate)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	dependency := models.ProjectDependency{Type: req.Type, ProjectID: req.ProjectID}
+	err = tx.QueryRow("SELECT name, status, launch_date FROM projects WHERE id = $1 AND deleted_at IS NULL", req.ProjectID).
+		Scan(&dependency.ProjectName, &dependency.Status, &dependency.LaunchDate)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Dependent project not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	if dependency.LaunchDate != nil {
+		date := derefDate(dependency.LaunchDate)
+		dependency.LaunchDate = &date
+	}
+
+	var duplicate bool
+	err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM project_dependencies WHERE blocker_id = $1 AND blocked_id = $2)",
+		blockerID, blockedID).Scan(&duplicate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if duplicate {
+		c.JSON(http.StatusConflict, gin.H{"error": "Dependency already exists"})
+		return
+	}
+
+	cycle, err := findDependencyCycle(tx, blockerID, blockedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if cycle != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Dependency would create a cycle: " + strings.Join(cycle, " -> "),
+			"code":  "DEPENDENCY_CYCLE",
+			"cycle": cycle,
+		})
+		return
+	}
+
+	userID, _, _, _ := middleware.GetCurrentUser(c)
+	dependency.ID = newID("dep")
+	dependency.CreatedBy = userID
+	err = tx.QueryRow(`
+		INSERT INTO project_dependencies (id, blocker_id, blocked_id, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at`,
+		dependency.ID, blockerID, blockedID, userID).Scan(&dependency.CreatedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save dependency: " + err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"dependency": dependency,
+		"warnings":   dependencyWarnings(projectID, derefDate(launchDate), []models.ProjectDependency{dependency}),
+	})
+}
+
+// DeleteProjectDependency 删除项目的某条依赖关系（任一方向）
+func (h *Handler) DeleteProjectDependency(c *gin.Context) {
+	projectID := c.Param("projectId")
+	dependencyID := c.Param("dependencyId")
+
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	result, err := h.db.Exec(
+		"DELETE FROM project_dependencies WHERE id = $1 AND (blocker_id = $2 OR blocked_id = $2)",
+		dependencyID, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dependency not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDependencyPath(t *testing.T) {
+	edges := map[string][]string{
+		"a": {"b", "d"},
+		"b": {"c"},
+		"d": {"c"},
+		"c": {"e"},
+	}
+	tests := []struct {
+		from, to string
+		want     []string
+	}{
+		{"a", "e", []string{"a", "b", "c", "e"}},
+		{"d", "e", []string{"d", "c", "e"}},
+		{"a", "a", []string{"a"}},
+		{"e", "a", nil},
+		{"x", "a", nil},
+	}
+	for _, tt := range tests {
+		if got := dependencyPath(edges, tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("dependencyPath(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestNewDependencyCycle(t *testing.T) {
+	// a 阻塞 b，b 阻塞 c
+	edges := map[string][]string{"a": {"b"}, "b": {"c"}}
+	tests := []struct {
+		name             string
+		blocker, blocked string
+		want             []string
+	}{
+		{"closes a cycle", "c", "a", []string{"a", "b", "c", "a"}},
+		{"direct reverse edge", "b", "a", []string{"a", "b", "a"}},
+		{"no cycle", "a", "c", nil},
+		{"unrelated project", "d", "a", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newDependencyCycle(edges, tt.blocker, tt.blocked); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newDependencyCycle(%s, %s) = %v, want %v", tt.blocker, tt.blocked, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDependencyCycleThrough(t *testing.T) {
+	// b 在回收站期间新增了 c -> a，恢复 b 后 a -> b -> c -> a 成环
+	edges := map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"a"}, "d": {"a"}}
+	if got, want := dependencyCycleThrough(edges, "b"), []string{"b", "c", "a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cycle through b = %v, want %v", got, want)
+	}
+	if got := dependencyCycleThrough(edges, "d"); got != nil {
+		t.Errorf("cycle through d = %v, want nil", got)
+	}
+}
+
+func TestDependencyWarnings(t *testing.T) {
+	s := func(v string) *string { return &v }
+	dependencies := []models.ProjectDependency{
+		{Type: dependencyBlocks, ProjectID: "early", LaunchDate: s("2026-10-01")},
+		{Type: dependencyBlocks, ProjectID: "late", LaunchDate: s("2026-12-01")},
+		{Type: dependencyBlockedBy, ProjectID: "blocker", LaunchDate: s("2026-11-15")},
+		{Type: dependencyBlockedBy, ProjectID: "undated"},
+	}
+	warnings := dependencyWarnings("p", "2026-11-01", dependencies)
+	var got [][2]string
+	for _, w := range warnings {
+		if w.Code != dependentLaunchesBeforeBlocker {
+			t.Errorf("code = %s", w.Code)
+		}
+		got = append(got, [2]string{w.BlockerID, w.BlockedID})
+	}
+	want := [][2]string{{"p", "early"}, {"blocker", "p"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("warnings = %v, want %v", got, want)
+	}
+	if warnings := dependencyWarnings("p", "", dependencies); warnings != nil {
+		t.Errorf("project without launch date produced warnings %+v", warnings)
+	}
+}
+
+func TestRestoreProjectDependencyCycle(t *testing.T) {
+	db := openTestDB(t)
+	h := &Handler{db: db}
+	a, b, c := "test-dep-restore-a", "test-dep-restore-b", "test-dep-restore-c"
+	for _, id := range []string{a, b, c} {
+		insertTestProject(t, db, id, id, "P1", "开发中")
+	}
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM project_dependencies WHERE blocker_id IN ($1, $2, $3)", a, b, c)
+	})
+	insertEdge := func(blocker, blocked string) {
+		t.Helper()
+		if _, err := db.Exec(`INSERT INTO project_dependencies (id, blocker_id, blocked_id) VALUES ($1, $2, $3)`,
+			newID("dep"), blocker, blocked); err != nil {
+			t.Fatal(err)
+		}
+	}
+	insertEdge(a, b)
+	insertEdge(b, c)
+
+	// b 在回收站中时，c -> a 不会与 a -> b -> c 成环
+	if _, err := db.Exec("UPDATE projects SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1", b); err != nil {
+		t.Fatal(err)
+	}
+	cycle, err := findDependencyCycle(db, c, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cycle != nil {
+		t.Fatalf("unexpected cycle through a trashed project: %v", cycle)
+	}
+	insertEdge(c, a)
+
+	// 恢复 b 会形成环，应返回 409 且 b 留在回收站中
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("POST", "/api/projects/"+b+"/restore", nil)
+	ctx.Params = gin.Params{{Key: "projectId", Value: b}}
+	h.RestoreProject(ctx)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Code  string   `json:"code"`
+		Cycle []string `json:"cycle"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != "DEPENDENCY_CYCLE" || !reflect.DeepEqual(body.Cycle, []string{b, c, a, b}) {
+		t.Errorf("response = %+v", body)
+	}
+	var trashed bool
+	if err := db.QueryRow("SELECT deleted_at IS NOT NULL FROM projects WHERE id = $1", b).Scan(&trashed); err != nil {
+		t.Fatal(err)
+	}
+	if !trashed {
+		t.Error("project was restored despite the dependency cycle")
+	}
+}
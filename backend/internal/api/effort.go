@@ -0,0 +1,289 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"project-management-backend/internal/middleware"
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxEstimatePersonDays 单个角色预估工作量的上限（人天）
+const maxEstimatePersonDays = 100000
+
+// HolidayCalendar 节假日（YYYY-MM-DD），统计工作日时与周末一同排除
+type HolidayCalendar map[string]bool
+
+// NewHolidayCalendar 解析节假日列表，日期格式为 YYYY-MM-DD
+func NewHolidayCalendar(dates []string) (HolidayCalendar, error) {
+	calendar := make(HolidayCalendar, len(dates))
+	for _, date := range dates {
+		if !isValidDate(date) {
+			return nil, fmt.Errorf("invalid holiday %q, expected YYYY-MM-DD", date)
+		}
+		calendar[date] = true
+	}
+	return calendar, nil
+}
+
+// countWorkingDays 返回 [from, to] 范围内除周末与节假日以外的天数
+func (hc HolidayCalendar) countWorkingDays(from, to time.Time) int {
+	days := 0
+	for _, day := range workingDaysBetween(from, to) {
+		if !hc[day.Format("2006-01-02")] {
+			days++
+		}
+	}
+	return days
+}
+
+// roundPersonDays 人天保留两位小数
+func roundPersonDays(days float64) float64 {
+	return math.Round(days*100) / 100
+}
+
+// effortVariance 计算已排期相对预估的偏差（人天与百分比），未填写预估时均为 nil，预估为 0 时百分比为 nil
+func effortVariance(estimate *float64, booked float64) (*float64, *float64) {
+	if estimate == nil {
+		return nil, nil
+	}
+	days := roundPersonDays(booked - *estimate)
+	if *estimate == 0 {
+		return &days, nil
+	}
+	percent := math.Round((booked-*estimate) / *estimate * 10000) / 100
+	return &days, &percent
+}
+
+// loadEffortEstimate 读取项目某角色的预估工作量
+func (h *Handler) loadEffortEstimate(projectID, roleKey string) (models.EffortEstimate, error) {
+	var estimate models.EffortEstimate
+	err := h.db.QueryRow(`
+		SELECT role_key, person_days, updated_by, updated_at
+		FROM project_effort_estimates
+		WHERE project_id = $1 AND role_key = $2`, projectID, roleKey,
+	).Scan(&estimate.RoleKey, &estimate.PersonDays, &estimate.UpdatedBy, &estimate.UpdatedAt)
+	return estimate, err
+}
+
+// GetProjectEffort 项目工作量预估与时段实际排期的对比
+// 已排期人天按时段覆盖的工作日（排除周末与节假日）乘以投入比例计算，按角色和成员汇总；
+// 项目合计的偏差为全部已排期人天相对各角色预估之和，未填写任何预估时为 null
+func (h *Handler) GetProjectEffort(c *gin.Context) {
+	projectID := c.Param("projectId")
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	estimates := make(map[string]float64)
+	rows, err := h.db.Query("SELECT role_key, person_days FROM project_effort_estimates WHERE project_id = $1", projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for rows.Next() {
+		var roleKey string
+		var personDays float64
+		if err := rows.Scan(&roleKey, &personDays); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		estimates[roleKey] = personDays
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err = h.db.Query(`
+		SELECT ts.role_key, ts.user_id, COALESCE(u.name, ''), ts.start_date, ts.end_date, ts.allocation_percent
+		FROM time_slots ts
+		LEFT JOIN users u ON u.id = ts.user_id
+		WHERE ts.project_id = $1 AND ts.start_date IS NOT NULL AND ts.end_date IS NOT NULL
+		ORDER BY ts.role_key, ts.user_id, ts.start_date`, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	members := make(map[string]map[string]*models.EffortMember)
+	memberOrder := make(map[string][]string)
+	for rows.Next() {
+		var roleKey, userID, name string
+		var startDate, endDate *string
+		var allocation int
+		if err := rows.Scan(&roleKey, &userID, &name, &startDate, &endDate, &allocation); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		start, err := time.Parse("2006-01-02", derefDate(startDate))
+		if err != nil {
+			continue
+		}
+		end, err := time.Parse("2006-01-02", derefDate(endDate))
+		if err != nil {
+			continue
+		}
+
+		if members[roleKey] == nil {
+			members[roleKey] = make(map[string]*models.EffortMember)
+		}
+		member, ok := members[roleKey][userID]
+		if !ok {
+			member = &models.EffortMember{UserID: userID, Name: name}
+			members[roleKey][userID] = member
+			memberOrder[roleKey] = append(memberOrder[roleKey], userID)
+		}
+		days := h.holidays.countWorkingDays(start, end)
+		member.BookedDays += days
+		member.BookedPersonDays += float64(days*allocation) / fullAllocation
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	definedRoles, err := h.loadProjectRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load roles: " + err.Error()})
+		return
+	}
+	// 按角色表顺序输出，已不在角色表中的角色按键排在最后
+	var roleOrder []string
+	roleNames := make(map[string]string, len(definedRoles))
+	for _, role := range definedRoles {
+		roleNames[role.Key] = role.Name
+		roleOrder = append(roleOrder, role.Key)
+	}
+	var undefined []string
+	for roleKey := range estimates {
+		if _, ok := roleNames[roleKey]; !ok {
+			undefined = appendUnique(undefined, roleKey)
+		}
+	}
+	for roleKey := range members {
+		if _, ok := roleNames[roleKey]; !ok {
+			undefined = appendUnique(undefined, roleKey)
+		}
+	}
+	sort.Strings(undefined)
+	roleOrder = append(roleOrder, undefined...)
+
+	report := models.EffortReport{ProjectID: projectID, Roles: []models.EffortRole{}}
+	var estimatedTotal *float64
+	for _, roleKey := range roleOrder {
+		estimate, hasEstimate := estimates[roleKey]
+		if !hasEstimate && members[roleKey] == nil {
+			continue
+		}
+		role := models.EffortRole{RoleKey: roleKey, RoleName: roleNames[roleKey], Members: []models.EffortMember{}}
+		if role.RoleName == "" {
+			role.RoleName = roleKey
+		}
+		for _, userID := range memberOrder[roleKey] {
+			member := members[roleKey][userID]
+			role.BookedPersonDays += member.BookedPersonDays
+			member.BookedPersonDays = roundPersonDays(member.BookedPersonDays)
+			role.Members = append(role.Members, *member)
+		}
+		if hasEstimate {
+			role.EstimatedPersonDays = &estimate
+			if estimatedTotal == nil {
+				estimatedTotal = new(float64)
+			}
+			*estimatedTotal += estimate
+		}
+		role.VariancePersonDays, role.VariancePercent = effortVariance(role.EstimatedPersonDays, role.BookedPersonDays)
+		report.BookedPersonDays += role.BookedPersonDays
+		role.BookedPersonDays = roundPersonDays(role.BookedPersonDays)
+		report.Roles = append(report.Roles, role)
+	}
+
+	if estimatedTotal != nil {
+		*estimatedTotal = roundPersonDays(*estimatedTotal)
+	}
+	report.EstimatedPersonDays = estimatedTotal
+	report.VariancePersonDays, report.VariancePercent = effortVariance(estimatedTotal, report.BookedPersonDays)
+	report.BookedPersonDays = roundPersonDays(report.BookedPersonDays)
+	c.JSON(http.StatusOK, report)
+}
+
+// SetProjectEffortEstimate 设置项目某角色的预估工作量（人天）
+func (h *Handler) SetProjectEffortEstimate(c *gin.Context) {
+	projectID := c.Param("projectId")
+	roleKey := c.Param("role")
+
+	var req struct {
+		PersonDays *float64 `json:"personDays"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.PersonDays == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid estimate", "fields": gin.H{"personDays": "is required"}})
+		return
+	}
+	if *req.PersonDays < 0 || *req.PersonDays > maxEstimatePersonDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid estimate", "fields": gin.H{"personDays": fmt.Sprintf("must be between 0 and %d", maxEstimatePersonDays)}})
+		return
+	}
+
+	if !h.checkTimeSlotRole(c, roleKey) {
+		return
+	}
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	var updatedBy *string
+	if userID, _, _, _ := middleware.GetCurrentUser(c); userID != "" {
+		updatedBy = &userID
+	}
+	_, err := h.db.Exec(`
+		INSERT INTO project_effort_estimates (project_id, role_key, person_days, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (project_id, role_key)
+		DO UPDATE SET person_days = EXCLUDED.person_days, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at`,
+		projectID, roleKey, roundPersonDays(*req.PersonDays), updatedBy, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save estimate: " + err.Error()})
+		return
+	}
+
+	estimate, err := h.loadEffortEstimate(projectID, roleKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, estimate)
+}
+
+// DeleteProjectEffortEstimate 删除项目某角色的预估工作量
+func (h *Handler) DeleteProjectEffortEstimate(c *gin.Context) {
+	projectID := c.Param("projectId")
+	roleKey := c.Param("role")
+
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	result, err := h.db.Exec("DELETE FROM project_effort_estimates WHERE project_id = $1 AND role_key = $2", projectID, roleKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Estimate not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
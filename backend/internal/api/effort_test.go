@@ -0,0 +1,95 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func mustDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestNewHolidayCalendar(t *testing.T) {
+	if _, err := NewHolidayCalendar([]string{"2026-10-01", "2026-13-01"}); err == nil {
+		t.Error("expected an error for an invalid holiday")
+	}
+	calendar, err := NewHolidayCalendar([]string{"2026-10-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !calendar["2026-10-01"] {
+		t.Error("holiday missing from calendar")
+	}
+}
+
+func TestCountWorkingDays(t *testing.T) {
+	holidays := HolidayCalendar{"2026-10-01": true, "2026-10-02": true, "2026-10-03": true}
+	tests := []struct {
+		name     string
+		calendar HolidayCalendar
+		from, to string
+		want     int
+	}{
+		{"two weeks", nil, "2026-09-28", "2026-10-09", 10},
+		{"two weeks with holidays", holidays, "2026-09-28", "2026-10-09", 8},
+		{"holiday on saturday", holidays, "2026-10-03", "2026-10-03", 0},
+		{"weekend only", nil, "2026-10-10", "2026-10-11", 0},
+		{"single day", nil, "2026-10-12", "2026-10-12", 1},
+		{"to before from", nil, "2026-10-12", "2026-10-09", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.calendar.countWorkingDays(mustDate(t, tt.from), mustDate(t, tt.to))
+			if got != tt.want {
+				t.Errorf("countWorkingDays(%s, %s) = %d, want %d", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEffortVariance(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name        string
+		estimate    *float64
+		booked      float64
+		wantDays    *float64
+		wantPercent *float64
+	}{
+		{"no estimate", nil, 5, nil, nil},
+		{"zero estimate", f(0), 2.5, f(2.5), nil},
+		{"over estimate", f(3.5), 4, f(0.5), f(14.29)},
+		{"under estimate", f(10), 7.5, f(-2.5), f(-25)},
+		{"on estimate", f(4), 4, f(0), f(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days, percent := effortVariance(tt.estimate, tt.booked)
+			if !equalFloatPtr(days, tt.wantDays) {
+				t.Errorf("days = %v, want %v", fmtFloatPtr(days), fmtFloatPtr(tt.wantDays))
+			}
+			if !equalFloatPtr(percent, tt.wantPercent) {
+				t.Errorf("percent = %v, want %v", fmtFloatPtr(percent), fmtFloatPtr(tt.wantPercent))
+			}
+		})
+	}
+}
+
+func equalFloatPtr(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func fmtFloatPtr(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
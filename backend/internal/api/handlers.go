@@ -19,69 +19,41 @@ import (
 )
 
 type Handler struct {
-	db *sql.DB
+	db          *sql.DB
+	attachments AttachmentConfig
+	holidays    HolidayCalendar
 }
 
-func NewHandler(db *sql.DB) *Handler {
-	return &Handler{db: db}
+func NewHandler(db *sql.DB, attachments AttachmentConfig, holidays HolidayCalendar) *Handler {
+	return &Handler{db: db, attachments: attachments, holidays: holidays}
 }
 
-// GetProjects 获取所有项目
+// GetProjects 获取项目列表，支持筛选、排序与游标分页
+// 总数通过 X-Total-Count 返回，下一页游标通过 X-Next-Cursor 返回
 func (h *Handler) GetProjects(c *gin.Context) {
-	query := `
-		SELECT id, name, priority, business_problem, key_result_ids, weekly_update, 
-		       last_week_update, status, product_managers, backend_developers, 
-		       frontend_developers, qa_testers, proposal_date, launch_date, 
-		       created_at, followers, comments, change_log
-		FROM projects
-		ORDER BY created_at DESC
-	`
+	q, err := parseProjectListQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	rows, err := h.db.Query(query)
+	total, err := h.countProjects(q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	defer rows.Close()
-
-	var projects []models.Project
-	var projectIDs []string
 
-	for rows.Next() {
-		var p models.Project
-		var keyResultIds pq.StringArray
-		var followers pq.StringArray
-		var productManagers, backendDevelopers, frontendDevelopers, qaTesters []byte
-		var comments, changeLog []byte
-
-		err := rows.Scan(
-			&p.ID, &p.Name, &p.Priority, &p.BusinessProblem, &keyResultIds,
-			&p.WeeklyUpdate, &p.LastWeekUpdate, &p.Status, &productManagers,
-			&backendDevelopers, &frontendDevelopers, &qaTesters,
-			&p.ProposalDate, &p.LaunchDate, &p.CreatedAt, &followers, &comments, &changeLog,
-		)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-
-		// 转换数组类型
-		p.KeyResultIds = []string(keyResultIds)
-		p.Followers = []string(followers)
-
-		// 解析JSONB字段
-		json.Unmarshal(productManagers, &p.ProductManagers)
-		json.Unmarshal(backendDevelopers, &p.BackendDevelopers)
-		json.Unmarshal(frontendDevelopers, &p.FrontendDevelopers)
-		json.Unmarshal(qaTesters, &p.QaTesters)
-		json.Unmarshal(comments, &p.Comments)
-		json.Unmarshal(changeLog, &p.ChangeLog)
+	projects, nextCursor, err := h.queryProjects(q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
+	var projectIDs []string
+	for i := range projects {
 		// 初始化空的TimeSlots
-		h.initializeEmptyTimeSlots(&p)
-
-		projects = append(projects, p)
-		projectIDs = append(projectIDs, p.ID)
+		h.initializeEmptyTimeSlots(&projects[i])
+		projectIDs = append(projectIDs, projects[i].ID)
 	}
 
 	// 批量加载所有项目的时段数据（优化N+1查询问题）
@@ -93,19 +65,109 @@ func (h *Handler) GetProjects(c *gin.Context) {
 		}
 	}
 
+	// 里程碑概览（下一个里程碑、逾期里程碑）与任务完成率
+	if err := h.loadMilestoneSummaries(projects); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load milestones: " + err.Error()})
+		return
+	}
+	if err := h.loadTaskSummaries(projects); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tasks: " + err.Error()})
+		return
+	}
+	if err := h.loadProjectLabels(projects); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load labels: " + err.Error()})
+		return
+	}
+	if err := h.loadProjectLinks(projects); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load links: " + err.Error()})
+		return
+	}
+
+	for i := range projects {
+		omitProjectHistory(c, &projects[i])
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	if nextCursor != "" {
+		c.Header("X-Next-Cursor", nextCursor)
+	}
 	c.JSON(http.StatusOK, projects)
 }
 
-// loadTimeSlots 加载项目的多时段数据
-func (h *Handler) loadTimeSlots(project *models.Project) error {
+// GetProject 获取单个项目（含多时段数据）
+// 可通过 includeComments=false / includeChangeLog=false 省略评论与变更日志
+func (h *Handler) GetProject(c *gin.Context) {
+	projectID := c.Param("projectId")
+
+	project, err := h.getProjectByID(projectID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	projects := []models.Project{project}
+	if err := h.loadMilestoneSummaries(projects); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load milestones: " + err.Error()})
+		return
+	}
+	if err := h.loadTaskSummaries(projects); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tasks: " + err.Error()})
+		return
+	}
+	if err := h.loadProjectLabels(projects); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load labels: " + err.Error()})
+		return
+	}
+	if err := h.loadProjectLinks(projects); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load links: " + err.Error()})
+		return
+	}
+	project = projects[0]
+
+	omitProjectHistory(c, &project)
+	c.Header("ETag", projectETag(project.Version))
+	c.JSON(http.StatusOK, project)
+}
+
+// getProjectByID 读取单个未删除的项目及其时段数据，不存在时返回 sql.ErrNoRows
+func (h *Handler) getProjectByID(projectID string) (models.Project, error) {
+	project, err := scanProject(h.db.QueryRow("SELECT "+projectColumns+" FROM projects WHERE id = $1 AND deleted_at IS NULL", projectID))
+	if err != nil {
+		return project, err
+	}
+
+	h.initializeEmptyTimeSlots(&project)
+	if err := h.loadTimeSlots(h.db, &project); err != nil {
+		return project, fmt.Errorf("failed to load time slots: %w", err)
+	}
+
+	return project, nil
+}
+
+// omitProjectHistory 根据查询参数省略评论和变更日志，减小响应体积
+func omitProjectHistory(c *gin.Context, project *models.Project) {
+	if c.Query("includeComments") == "false" {
+		project.Comments = nil
+	}
+	if c.Query("includeChangeLog") == "false" {
+		project.ChangeLog = nil
+	}
+}
+
+// loadTimeSlots 加载项目的多时段数据，q 可以是事务以读取事务内的修改
+func (h *Handler) loadTimeSlots(q queryer, project *models.Project) error {
 	query := `
-		SELECT user_id, role_key, start_date, end_date, description
+		SELECT ` + timeSlotColumns + `, user_id, role_key
 		FROM time_slots 
 		WHERE project_id = $1
-		ORDER BY role_key, user_id, start_date
+		ORDER BY role_key, user_id, start_date, id
 	`
 
-	rows, err := h.db.Query(query, project.ID)
+	rows, err := q.Query(query, project.ID)
 	if err != nil {
 		return err
 	}
@@ -115,26 +177,12 @@ func (h *Handler) loadTimeSlots(project *models.Project) error {
 	timeSlotMap := make(map[string]map[string][]models.TimeSlot)
 
 	for rows.Next() {
-		var userID, roleKey, description string
-		var startDate, endDate *string
-
-		err := rows.Scan(&userID, &roleKey, &startDate, &endDate, &description)
+		var userID, roleKey string
+		timeSlot, err := scanTimeSlot(rows, &userID, &roleKey)
 		if err != nil {
 			return err
 		}
 
-		timeSlot := models.TimeSlot{
-			ID:          "slot_" + userID + "_" + roleKey,
-			Description: &description,
-		}
-
-		if startDate != nil {
-			timeSlot.StartDate = *startDate
-		}
-		if endDate != nil {
-			timeSlot.EndDate = *endDate
-		}
-
 		if timeSlotMap[roleKey] == nil {
 			timeSlotMap[roleKey] = make(map[string][]models.TimeSlot)
 		}
@@ -142,10 +190,9 @@ func (h *Handler) loadTimeSlots(project *models.Project) error {
 	}
 
 	// 将时段数据分配给对应的团队成员
-	h.assignTimeSlotsToMembers(project.ProductManagers, timeSlotMap["productManagers"])
-	h.assignTimeSlotsToMembers(project.BackendDevelopers, timeSlotMap["backendDevelopers"])
-	h.assignTimeSlotsToMembers(project.FrontendDevelopers, timeSlotMap["frontendDevelopers"])
-	h.assignTimeSlotsToMembers(project.QaTesters, timeSlotMap["qaTesters"])
+	for _, roleKey := range projectRoleKeys(project) {
+		h.assignTimeSlotsToMembers(roleMembers(project, roleKey), timeSlotMap[roleKey])
+	}
 
 	return nil
 }
@@ -164,17 +211,11 @@ func (h *Handler) assignTimeSlotsToMembers(members []models.TeamMember, userTime
 
 // initializeEmptyTimeSlots 初始化空的时段数据
 func (h *Handler) initializeEmptyTimeSlots(project *models.Project) {
-	for i := range project.ProductManagers {
-		project.ProductManagers[i].TimeSlots = []models.TimeSlot{}
-	}
-	for i := range project.BackendDevelopers {
-		project.BackendDevelopers[i].TimeSlots = []models.TimeSlot{}
-	}
-	for i := range project.FrontendDevelopers {
-		project.FrontendDevelopers[i].TimeSlots = []models.TimeSlot{}
-	}
-	for i := range project.QaTesters {
-		project.QaTesters[i].TimeSlots = []models.TimeSlot{}
+	for _, roleKey := range projectRoleKeys(project) {
+		members := roleMembers(project, roleKey)
+		for i := range members {
+			members[i].TimeSlots = []models.TimeSlot{}
+		}
 	}
 }
 
@@ -193,10 +234,10 @@ func (h *Handler) loadAllTimeSlots(projects []models.Project, projectIDs []strin
 	}
 
 	query := `
-		SELECT project_id, user_id, role_key, start_date, end_date, description
+		SELECT ` + timeSlotColumns + `, project_id, user_id, role_key
 		FROM time_slots 
 		WHERE project_id IN (` + strings.Join(placeholders, ",") + `)
-		ORDER BY project_id, role_key, user_id, start_date
+		ORDER BY project_id, role_key, user_id, start_date, id
 	`
 
 	rows, err := h.db.Query(query, args...)
@@ -215,26 +256,12 @@ func (h *Handler) loadAllTimeSlots(projects []models.Project, projectIDs []strin
 	projectTimeSlots := make(map[string]map[string]map[string][]models.TimeSlot)
 
 	for rows.Next() {
-		var projectID, userID, roleKey, description string
-		var startDate, endDate *string
-
-		err := rows.Scan(&projectID, &userID, &roleKey, &startDate, &endDate, &description)
+		var projectID, userID, roleKey string
+		timeSlot, err := scanTimeSlot(rows, &projectID, &userID, &roleKey)
 		if err != nil {
 			return err
 		}
 
-		timeSlot := models.TimeSlot{
-			ID:          "slot_" + userID + "_" + roleKey,
-			Description: &description,
-		}
-
-		if startDate != nil {
-			timeSlot.StartDate = *startDate
-		}
-		if endDate != nil {
-			timeSlot.EndDate = *endDate
-		}
-
 		if projectTimeSlots[projectID] == nil {
 			projectTimeSlots[projectID] = make(map[string]map[string][]models.TimeSlot)
 		}
@@ -248,10 +275,9 @@ func (h *Handler) loadAllTimeSlots(projects []models.Project, projectIDs []strin
 	for projectID, timeSlotMap := range projectTimeSlots {
 		if projectIndex, exists := projectIndexMap[projectID]; exists {
 			project := &projects[projectIndex]
-			h.assignTimeSlotsToMembers(project.ProductManagers, timeSlotMap["productManagers"])
-			h.assignTimeSlotsToMembers(project.BackendDevelopers, timeSlotMap["backendDevelopers"])
-			h.assignTimeSlotsToMembers(project.FrontendDevelopers, timeSlotMap["frontendDevelopers"])
-			h.assignTimeSlotsToMembers(project.QaTesters, timeSlotMap["qaTesters"])
+			for _, roleKey := range projectRoleKeys(project) {
+				h.assignTimeSlotsToMembers(roleMembers(project, roleKey), timeSlotMap[roleKey])
+			}
 		}
 	}
 
@@ -270,6 +296,8 @@ func (h *Handler) CreateProject(c *gin.Context) {
 	now := time.Now()
 	project.ID = "p" + strconv.FormatInt(now.UnixNano(), 10)
 	project.CreatedAt = now.Format(time.RFC3339)
+	project.StatusChangedAt = &project.CreatedAt
+	project.Version = 1
 
 	// 处理必填字段的默认值
 	if project.Name == "" {
@@ -278,8 +306,19 @@ func (h *Handler) CreateProject(c *gin.Context) {
 	if project.Priority == "" {
 		project.Priority = "日常需求"
 	}
+
+	// 状态需符合状态流转定义，未指定时使用初始状态
+	workflow, err := h.loadWorkflow()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workflow: " + err.Error()})
+		return
+	}
 	if project.Status == "" {
-		project.Status = "未开始"
+		project.Status = workflow.InitialStatus
+	}
+	if transitionErr := checkStatusTransition(workflow, "", project.Status); transitionErr != nil {
+		respondStatusTransitionError(c, transitionErr)
+		return
 	}
 
 	// 处理日期字段的空字符串问题
@@ -309,13 +348,53 @@ func (h *Handler) CreateProject(c *gin.Context) {
 	if project.QaTesters == nil {
 		project.QaTesters = []models.TeamMember{}
 	}
+	foldBuiltInMembers(&project)
 	if project.Comments == nil {
 		project.Comments = []models.Comment{}
 	}
-	if project.ChangeLog == nil {
-		project.ChangeLog = []models.ChangeLogEntry{}
+
+	// 自定义角色需已在角色表中定义
+	if err := h.checkProjectMemberRoles(&project); err != nil {
+		respondMemberRolesError(c, err)
+		return
+	}
+
+	// 自定义字段的值需符合字段定义
+	if project.CustomFields == nil {
+		project.CustomFields = map[string]interface{}{}
+	}
+	fieldErrors, err := h.checkCustomFieldValues(&project, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load custom fields: " + err.Error()})
+		return
+	}
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project", "fields": fieldErrors})
+		return
+	}
+
+	// 健康度非绿色时必须填写原因，上周健康度由周会滚动生成
+	project.LastWeekHealth = nil
+	if fieldErrors := checkProjectHealth(&project, nil, true); len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project", "fields": fieldErrors})
+		return
 	}
 
+	// 校验时段：日期格式、起止顺序、同一成员的时段不重叠
+	if fieldErrors := validateProjectTimeSlots(&project); len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time slots", "fields": fieldErrors})
+		return
+	}
+	warnings := projectSlotWarnings(&project)
+	if len(warnings) > 0 && strictSlotValidation(c) {
+		respondSlotWarningsRejected(c, warnings)
+		return
+	}
+
+	// 变更日志由服务端生成，忽略客户端提交的内容
+	userID, _, _, _ := middleware.GetCurrentUser(c)
+	project.ChangeLog = []models.ChangeLogEntry{newCreationLogEntry(&project, userID, now)}
+
 	// 开始事务
 	tx, err := h.db.Begin()
 	if err != nil {
@@ -324,6 +403,37 @@ func (h *Handler) CreateProject(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
+	if err := insertProject(tx, &project); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 插入多时段数据
+	if err := insertTimeSlots(tx, &project, nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save time slots: " + err.Error()})
+		return
+	}
+
+	// 记录初始健康度
+	if project.Health != nil {
+		if err := insertHealthChange(tx, &project, userID, now); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save health history: " + err.Error()})
+			return
+		}
+	}
+
+	// 提交事务
+	if err = tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.Header("ETag", projectETag(project.Version))
+	c.JSON(http.StatusCreated, projectResponse{Project: project, Warnings: warnings})
+}
+
+// insertProject 插入项目基本信息
+func insertProject(tx *sql.Tx, project *models.Project) error {
 	// 序列化JSONB字段
 	productManagersJSON, _ := json.Marshal(project.ProductManagers)
 	backendDevelopersJSON, _ := json.Marshal(project.BackendDevelopers)
@@ -331,107 +441,160 @@ func (h *Handler) CreateProject(c *gin.Context) {
 	qaTestersJSON, _ := json.Marshal(project.QaTesters)
 	commentsJSON, _ := json.Marshal(project.Comments)
 	changeLogJSON, _ := json.Marshal(project.ChangeLog)
+	membersJSON := marshalMembers(project.Members)
+	customFieldsJSON := marshalCustomFields(project.CustomFields)
 
-	// 插入项目基本信息
 	query := `
 		INSERT INTO projects (
 			id, name, priority, business_problem, key_result_ids, weekly_update, 
 			last_week_update, status, product_managers, backend_developers, 
 			frontend_developers, qa_testers, proposal_date, launch_date, 
-			created_at, followers, comments, change_log
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`
+			created_at, followers, comments, change_log, version, status_changed_at, members, custom_fields,
+			health, health_reason
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)`
 
-	_, err = tx.Exec(query,
+	_, err := tx.Exec(query,
 		project.ID, project.Name, project.Priority, project.BusinessProblem,
 		pq.Array(project.KeyResultIds), project.WeeklyUpdate, project.LastWeekUpdate,
 		project.Status, productManagersJSON, backendDevelopersJSON,
 		frontendDevelopersJSON, qaTestersJSON, project.ProposalDate, project.LaunchDate,
-		project.CreatedAt, pq.Array(project.Followers), commentsJSON, changeLogJSON)
+		project.CreatedAt, pq.Array(project.Followers), commentsJSON, changeLogJSON, project.Version, project.StatusChangedAt,
+		membersJSON, customFieldsJSON, project.Health, project.HealthReason)
+	return err
+}
 
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+// marshalMembers 序列化自定义角色成员，空值存为 {}
+func marshalMembers(members map[string]models.Role) []byte {
+	if len(members) == 0 {
+		return []byte("{}")
 	}
+	data, _ := json.Marshal(members)
+	return data
+}
 
-	// 插入多时段数据
-	timeSlotQuery := `
-		INSERT INTO time_slots (id, project_id, user_id, role_key, start_date, end_date, description)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+// marshalCustomFields 序列化自定义字段的值，空值存为 {}
+func marshalCustomFields(values map[string]interface{}) []byte {
+	if len(values) == 0 {
+		return []byte("{}")
+	}
+	data, _ := json.Marshal(values)
+	return data
+}
 
-	// 处理各个角色的多时段数据
-	roleMap := map[string][]models.TeamMember{
-		"productManagers":    project.ProductManagers,
-		"backendDevelopers":  project.BackendDevelopers,
-		"frontendDevelopers": project.FrontendDevelopers,
-		"qaTesters":          project.QaTesters,
+// saveProject 按版本号更新项目全部字段并递增版本号
+// 版本号不匹配时返回 false，成功时 project.Version 更新为新版本
+func saveProject(tx *sql.Tx, project *models.Project, expectedVersion int) (bool, error) {
+	// 序列化JSONB字段
+	productManagersJSON, _ := json.Marshal(project.ProductManagers)
+	backendDevelopersJSON, _ := json.Marshal(project.BackendDevelopers)
+	frontendDevelopersJSON, _ := json.Marshal(project.FrontendDevelopers)
+	qaTestersJSON, _ := json.Marshal(project.QaTesters)
+	commentsJSON, _ := json.Marshal(project.Comments)
+	changeLogJSON, _ := json.Marshal(project.ChangeLog)
+	membersJSON := marshalMembers(project.Members)
+	customFieldsJSON := marshalCustomFields(project.CustomFields)
+
+	updateQuery := `
+		UPDATE projects SET 
+			name = $2, priority = $3, business_problem = $4, key_result_ids = $5, 
+			weekly_update = $6, last_week_update = $7, status = $8, 
+			product_managers = $9, backend_developers = $10, 
+			frontend_developers = $11, qa_testers = $12, 
+			proposal_date = $13, launch_date = $14, followers = $15, 
+			comments = $16, change_log = $17, created_at = $18,
+			status_changed_at = $20, members = $21, custom_fields = $22,
+			health = $23, health_reason = $24, version = version + 1
+		WHERE id = $1 AND version = $19
+	`
+
+	result, err := tx.Exec(updateQuery,
+		project.ID, project.Name, project.Priority, project.BusinessProblem,
+		pq.Array(project.KeyResultIds), project.WeeklyUpdate, project.LastWeekUpdate,
+		project.Status, productManagersJSON, backendDevelopersJSON,
+		frontendDevelopersJSON, qaTestersJSON, project.ProposalDate, project.LaunchDate,
+		pq.Array(project.Followers), commentsJSON, changeLogJSON, project.CreatedAt,
+		expectedVersion, project.StatusChangedAt, membersJSON, customFieldsJSON, project.Health, project.HealthReason)
+	if err != nil {
+		return false, err
 	}
 
-	for roleKey, members := range roleMap {
-		for _, member := range members {
-			for _, timeSlot := range member.TimeSlots {
-				slotID := "slot_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return false, nil
+	}
+	project.Version = expectedVersion + 1
+	return true, nil
+}
 
-				var startDate, endDate interface{}
-				if timeSlot.StartDate != "" {
-					startDate = timeSlot.StartDate
-				}
-				if timeSlot.EndDate != "" {
-					endDate = timeSlot.EndDate
+// insertTimeSlots 将项目各角色成员的时段写入 time_slots 表
+// keepIDs 中的时段ID会被保留（用于重写同一项目的时段），其余时段生成新ID
+func insertTimeSlots(tx *sql.Tx, project *models.Project, keepIDs map[string]bool) error {
+	timeSlotQuery := `
+		INSERT INTO time_slots (id, project_id, user_id, role_key, start_date, end_date, description, allocation_percent, hours_per_week)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	used := make(map[string]bool)
+	for _, roleKey := range projectRoleKeys(project) {
+		role := roleMembers(project, roleKey)
+		for i := range role {
+			member := &role[i]
+			for j := range member.TimeSlots {
+				timeSlot := &member.TimeSlots[j]
+				if !keepIDs[timeSlot.ID] || used[timeSlot.ID] {
+					timeSlot.ID = newID("slot")
 				}
+				used[timeSlot.ID] = true
 
-				_, err = tx.Exec(timeSlotQuery,
-					slotID, project.ID, member.UserID, roleKey,
-					startDate, endDate, timeSlot.Description)
-
+				_, err := tx.Exec(timeSlotQuery,
+					timeSlot.ID, project.ID, member.UserID, roleKey,
+					nullableDate(timeSlot.StartDate), nullableDate(timeSlot.EndDate), timeSlot.Description,
+					timeSlot.AllocationPercent, timeSlot.HoursPerWeek)
 				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save time slots: " + err.Error()})
-					return
+					return err
 				}
 			}
 		}
 	}
+	return nil
+}
 
-	// 提交事务
-	if err = tx.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
-		return
+// replaceTimeSlots 用项目当前的团队数据重写其全部时段，已存在的时段保持原ID
+func replaceTimeSlots(tx *sql.Tx, project *models.Project) error {
+	rows, err := tx.Query("SELECT id FROM time_slots WHERE project_id = $1", project.ID)
+	if err != nil {
+		return err
+	}
+	existingIDs := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		existingIDs[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
 	}
 
-	c.JSON(http.StatusCreated, project)
+	if _, err := tx.Exec("DELETE FROM time_slots WHERE project_id = $1", project.ID); err != nil {
+		return err
+	}
+	return insertTimeSlots(tx, project, existingIDs)
 }
 
 // UpdateProject 更新项目
+// 请求体按 JSON Merge Patch (RFC 7396) 处理：未出现的字段保持不变，显式 null 清空字段
 func (h *Handler) UpdateProject(c *gin.Context) {
 	projectID := c.Param("projectId")
 
-	var updates models.Project
-	if err := c.ShouldBindJSON(&updates); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	patch, ok := bindMergePatch(c, "Patch")
+	if !ok {
 		return
 	}
 
 	// 首先获取现有项目
-	var existing models.Project
-	query := `
-		SELECT id, name, priority, business_problem, key_result_ids, weekly_update, 
-		       last_week_update, status, product_managers, backend_developers, 
-		       frontend_developers, qa_testers, proposal_date, launch_date, 
-		       created_at, followers, comments, change_log
-		FROM projects WHERE id = $1
-	`
-
-	var keyResultIds pq.StringArray
-	var followers pq.StringArray
-	var productManagers, backendDevelopers, frontendDevelopers, qaTesters []byte
-	var comments, changeLog []byte
-
-	err := h.db.QueryRow(query, projectID).Scan(
-		&existing.ID, &existing.Name, &existing.Priority, &existing.BusinessProblem, &keyResultIds,
-		&existing.WeeklyUpdate, &existing.LastWeekUpdate, &existing.Status, &productManagers,
-		&backendDevelopers, &frontendDevelopers, &qaTesters,
-		&existing.ProposalDate, &existing.LaunchDate, &existing.CreatedAt, &followers, &comments, &changeLog,
-	)
-
+	existing, err := scanProject(h.db.QueryRow("SELECT "+projectColumns+" FROM projects WHERE id = $1 AND deleted_at IS NULL", projectID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
@@ -441,67 +604,81 @@ func (h *Handler) UpdateProject(c *gin.Context) {
 		return
 	}
 
-	// 转换现有数据
-	existing.KeyResultIds = []string(keyResultIds)
-	existing.Followers = []string(followers)
-	json.Unmarshal(productManagers, &existing.ProductManagers)
-	json.Unmarshal(backendDevelopers, &existing.BackendDevelopers)
-	json.Unmarshal(frontendDevelopers, &existing.FrontendDevelopers)
-	json.Unmarshal(qaTesters, &existing.QaTesters)
-	json.Unmarshal(comments, &existing.Comments)
-	json.Unmarshal(changeLog, &existing.ChangeLog)
-
-	// 合并更新
-	if updates.Name != "" {
-		existing.Name = updates.Name
-	}
-	if updates.Priority != "" {
-		existing.Priority = updates.Priority
-	}
-	if updates.Status != "" {
-		existing.Status = updates.Status
-	}
-	if updates.BusinessProblem != nil {
-		existing.BusinessProblem = updates.BusinessProblem
-	}
-	if updates.WeeklyUpdate != nil {
-		existing.WeeklyUpdate = updates.WeeklyUpdate
-	}
-	if updates.LastWeekUpdate != nil {
-		existing.LastWeekUpdate = updates.LastWeekUpdate
-	}
-	if updates.ProposalDate != nil {
-		existing.ProposalDate = updates.ProposalDate
-	}
-	if updates.LaunchDate != nil {
-		existing.LaunchDate = updates.LaunchDate
-	}
-	if updates.KeyResultIds != nil {
-		existing.KeyResultIds = updates.KeyResultIds
+	// 乐观锁校验：客户端持有的版本已过期时拒绝覆盖
+	if !ifMatchSatisfied(c.GetHeader("If-Match"), existing.Version) {
+		h.respondVersionConflict(c, projectID)
+		return
 	}
-	if updates.Followers != nil {
-		existing.Followers = updates.Followers
+	expectedVersion := existing.Version
+
+	// 时段以 time_slots 表为准（可能已通过时段接口单独修改）
+	h.initializeEmptyTimeSlots(&existing)
+	if err := h.loadTimeSlots(h.db, &existing); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load time slots: " + err.Error()})
+		return
 	}
-	if updates.ProductManagers != nil {
-		existing.ProductManagers = updates.ProductManagers
+
+	// 合并更新
+	before := existing
+	teamUpdated, fieldErrors := applyProjectPatch(&existing, patch)
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project patch", "fields": fieldErrors})
+		return
 	}
-	if updates.BackendDevelopers != nil {
-		existing.BackendDevelopers = updates.BackendDevelopers
+	if _, ok := patch["members"]; ok {
+		if err := h.checkProjectMemberRoles(&existing); err != nil {
+			respondMemberRolesError(c, err)
+			return
+		}
 	}
-	if updates.FrontendDevelopers != nil {
-		existing.FrontendDevelopers = updates.FrontendDevelopers
+	if _, ok := patch["customFields"]; ok {
+		fieldErrors, err := h.checkCustomFieldValues(&existing, &before)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load custom fields: " + err.Error()})
+			return
+		}
+		if len(fieldErrors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project patch", "fields": fieldErrors})
+			return
+		}
 	}
-	if updates.QaTesters != nil {
-		existing.QaTesters = updates.QaTesters
+	_, healthPatched := patch["health"]
+	_, reasonPatched := patch["healthReason"]
+	if healthPatched || reasonPatched {
+		if fieldErrors := checkProjectHealth(&existing, &before, reasonPatched); len(fieldErrors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project patch", "fields": fieldErrors})
+			return
+		}
 	}
-	if updates.Comments != nil {
-		existing.Comments = updates.Comments
+
+	// 团队或项目日期范围变化时，严格模式下拒绝超出范围的时段
+	warnings := projectSlotWarnings(&existing)
+	_, proposedDatePatched := patch["proposedDate"]
+	_, launchDatePatched := patch["launchDate"]
+	if len(warnings) > 0 && strictSlotValidation(c) && (teamUpdated || proposedDatePatched || launchDatePatched) {
+		respondSlotWarningsRejected(c, warnings)
+		return
 	}
-	if updates.ChangeLog != nil {
-		existing.ChangeLog = updates.ChangeLog
+
+	// 状态变更需符合状态流转定义
+	if existing.Status != before.Status {
+		workflow, err := h.loadWorkflow()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workflow: " + err.Error()})
+			return
+		}
+		if transitionErr := checkStatusTransition(workflow, before.Status, existing.Status); transitionErr != nil {
+			respondStatusTransitionError(c, transitionErr)
+			return
+		}
 	}
-	if updates.CreatedAt != "" {
-		existing.CreatedAt = updates.CreatedAt
+
+	// 服务端对比合并前后的数据生成变更日志
+	userID, _, _, _ := middleware.GetCurrentUser(c)
+	now := time.Now()
+	if err := h.recordProjectChanges(&before, &existing, userID, now); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load users: " + err.Error()})
+		return
 	}
 
 	// 开始事务
@@ -512,102 +689,102 @@ func (h *Handler) UpdateProject(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	// 序列化JSONB字段
-	productManagersJSON, _ := json.Marshal(existing.ProductManagers)
-	backendDevelopersJSON, _ := json.Marshal(existing.BackendDevelopers)
-	frontendDevelopersJSON, _ := json.Marshal(existing.FrontendDevelopers)
-	qaTestersJSON, _ := json.Marshal(existing.QaTesters)
-	commentsJSON, _ := json.Marshal(existing.Comments)
-	changeLogJSON, _ := json.Marshal(existing.ChangeLog)
-
-	// 更新项目基本信息
-	updateQuery := `
-		UPDATE projects SET 
-			name = $2, priority = $3, business_problem = $4, key_result_ids = $5, 
-			weekly_update = $6, last_week_update = $7, status = $8, 
-			product_managers = $9, backend_developers = $10, 
-			frontend_developers = $11, qa_testers = $12, 
-			proposal_date = $13, launch_date = $14, followers = $15, 
-			comments = $16, change_log = $17, created_at = $18
-		WHERE id = $1
-	`
-
-	_, err = tx.Exec(updateQuery,
-		projectID, existing.Name, existing.Priority, existing.BusinessProblem,
-		pq.Array(existing.KeyResultIds), existing.WeeklyUpdate, existing.LastWeekUpdate,
-		existing.Status, productManagersJSON, backendDevelopersJSON,
-		frontendDevelopersJSON, qaTestersJSON, existing.ProposalDate, existing.LaunchDate,
-		pq.Array(existing.Followers), commentsJSON, changeLogJSON, existing.CreatedAt)
-
+	// 更新项目基本信息（版本号不匹配说明读取与写入之间被其他请求修改）
+	saved, err := saveProject(tx, &existing, expectedVersion)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if !saved {
+		tx.Rollback()
+		h.respondVersionConflict(c, projectID)
+		return
+	}
 
-	// 检查是否有团队成员更新，如果有则更新时段数据
-	hasTeamUpdates := updates.ProductManagers != nil || updates.BackendDevelopers != nil ||
-		updates.FrontendDevelopers != nil || updates.QaTesters != nil
-
-	if hasTeamUpdates {
-		// 删除现有的时段数据
-		_, err = tx.Exec("DELETE FROM time_slots WHERE project_id = $1", projectID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete existing time slots: " + err.Error()})
+	// 检查是否有团队成员更新，如果有则重写时段数据（保留已有时段ID）
+	if teamUpdated {
+		if err := replaceTimeSlots(tx, &existing); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save time slots: " + err.Error()})
 			return
 		}
+	}
 
-		// 插入新的时段数据
-		timeSlotQuery := `
-			INSERT INTO time_slots (id, project_id, user_id, role_key, start_date, end_date, description)
-			VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	// 健康度变化时记录历史
+	if healthChanged(&before, &existing) {
+		if err := insertHealthChange(tx, &existing, userID, now); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save health history: " + err.Error()})
+			return
+		}
+	}
 
-		roleMap := map[string][]models.TeamMember{
-			"productManagers":    existing.ProductManagers,
-			"backendDevelopers":  existing.BackendDevelopers,
-			"frontendDevelopers": existing.FrontendDevelopers,
-			"qaTesters":          existing.QaTesters,
+	// 上线时间变化时检查与依赖项目的先后顺序
+	var dependencyWarnings []models.DependencyWarning
+	if launchDatePatched {
+		if dependencyWarnings, err = projectDependencyWarnings(tx, &existing); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load dependencies: " + err.Error()})
+			return
 		}
+	}
 
-		for roleKey, members := range roleMap {
-			for _, member := range members {
-				for _, timeSlot := range member.TimeSlots {
-					slotID := "slot_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	// 提交事务
+	if err = tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
 
-					var startDate, endDate interface{}
-					if timeSlot.StartDate != "" {
-						startDate = timeSlot.StartDate
-					}
-					if timeSlot.EndDate != "" {
-						endDate = timeSlot.EndDate
-					}
+	c.Header("ETag", projectETag(existing.Version))
+	c.JSON(http.StatusOK, projectResponse{Project: existing, Warnings: warnings, DependencyWarnings: dependencyWarnings})
+}
 
-					_, err = tx.Exec(timeSlotQuery,
-						slotID, projectID, member.UserID, roleKey,
-						startDate, endDate, timeSlot.Description)
+// projectETag 根据版本号生成 ETag
+func projectETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
 
-					if err != nil {
-						c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save time slots: " + err.Error()})
-						return
-					}
-				}
-			}
+// ifMatchSatisfied 判断 If-Match 头是否与当前版本匹配，未提供时视为匹配
+func ifMatchSatisfied(ifMatch string, version int) bool {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" || ifMatch == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == projectETag(version) {
+			return true
 		}
 	}
+	return false
+}
 
-	// 提交事务
-	if err = tx.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+// respondVersionConflict 返回 409 及服务端当前的项目数据，便于客户端合并后重试
+func (h *Handler) respondVersionConflict(c *gin.Context, projectID string) {
+	current, err := h.getProjectByID(projectID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, existing)
+	c.Header("ETag", projectETag(current.Version))
+	c.JSON(http.StatusConflict, gin.H{
+		"error":          "Project has been modified by someone else",
+		"currentVersion": current.Version,
+		"currentProject": current,
+	})
 }
 
-// DeleteProject 删除项目
+// DeleteProject 删除项目（软删除，移入回收站）
 func (h *Handler) DeleteProject(c *gin.Context) {
 	projectID := c.Param("projectId")
+	userID, _, _, _ := middleware.GetCurrentUser(c)
 
-	result, err := h.db.Exec("DELETE FROM projects WHERE id = $1", projectID)
+	result, err := h.db.Exec(`
+		UPDATE projects
+		SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2, version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL`, projectID, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -734,20 +911,27 @@ func (h *Handler) UpdateOkrSet(c *gin.Context) {
 }
 
 // PerformWeeklyRollover 执行周会数据滚动
+// 同时记录各项目当前的健康度，用于比较下周健康度是否变差
 func (h *Handler) PerformWeeklyRollover(c *gin.Context) {
+	tx, err := h.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE projects 
-		SET last_week_update = weekly_update
-		WHERE weekly_update IS NOT NULL AND weekly_update != ''
+		SET last_week_update = weekly_update, version = version + 1
+		WHERE weekly_update IS NOT NULL AND weekly_update != '' AND deleted_at IS NULL
 		RETURNING id
 	`
 
-	rows, err := h.db.Query(query)
+	rows, err := tx.Query(query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	defer rows.Close()
 
 	var updatedProjectIds []string
 	for rows.Next() {
@@ -755,6 +939,17 @@ func (h *Handler) PerformWeeklyRollover(c *gin.Context) {
 		rows.Scan(&id)
 		updatedProjectIds = append(updatedProjectIds, id)
 	}
+	rows.Close()
+
+	if err := snapshotWeeklyHealth(tx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to snapshot health: " + err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"updatedProjectIds": updatedProjectIds})
 }
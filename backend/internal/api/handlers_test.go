@@ -0,0 +1,84 @@
+package api
+
+import (
+	"database/sql"
+	"testing"
+
+	"project-management-backend/internal/models"
+)
+
+func TestOmitProjectHistory(t *testing.T) {
+	tests := []struct {
+		query                    string
+		wantComments, wantChange bool
+	}{
+		{"", true, true},
+		{"includeComments=false", false, true},
+		{"includeChangeLog=false", true, false},
+		{"includeComments=false&includeChangeLog=false", false, false},
+		{"includeComments=0", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			project := models.Project{
+				Comments:  []models.Comment{{ID: "c1"}},
+				ChangeLog: []models.ChangeLogEntry{{ID: "l1"}},
+			}
+			omitProjectHistory(newQueryContext(tt.query), &project)
+			if (project.Comments != nil) != tt.wantComments {
+				t.Errorf("comments kept = %v, want %v", project.Comments != nil, tt.wantComments)
+			}
+			if (project.ChangeLog != nil) != tt.wantChange {
+				t.Errorf("change log kept = %v, want %v", project.ChangeLog != nil, tt.wantChange)
+			}
+		})
+	}
+}
+
+func TestGetProjectByID(t *testing.T) {
+	db := openTestDB(t)
+	h := &Handler{db: db}
+	id := "test-" + t.Name()
+	insertTestProject(t, db, id, "单项目读取", "P1", "开发中")
+
+	project, err := h.getProjectByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.ID != id || project.Name != "单项目读取" {
+		t.Errorf("got project %s %q", project.ID, project.Name)
+	}
+
+	if _, err := h.getProjectByID(id + "-missing"); err != sql.ErrNoRows {
+		t.Errorf("missing project error = %v, want sql.ErrNoRows", err)
+	}
+
+	// 回收站中的项目按不存在处理
+	if _, err := db.Exec("UPDATE projects SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1", id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.getProjectByID(id); err != sql.ErrNoRows {
+		t.Errorf("trashed project error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestIfMatchSatisfied(t *testing.T) {
+	tests := []struct {
+		ifMatch string
+		want    bool
+	}{
+		{"", true},
+		{"*", true},
+		{`"3"`, true},
+		{`W/"3"`, true},
+		{`"1", "3"`, true},
+		{`"2"`, false},
+		{`3`, false},
+		{`"30"`, false},
+	}
+	for _, tt := range tests {
+		if got := ifMatchSatisfied(tt.ifMatch, 3); got != tt.want {
+			t.Errorf("ifMatchSatisfied(%q, 3) = %v, want %v", tt.ifMatch, got, tt.want)
+		}
+	}
+}
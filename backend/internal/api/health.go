@@ -0,0 +1,165 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// healthRank 返回健康度的严重程度（green=0、amber=1、red=2），未知取值返回 -1
+func healthRank(health string) int {
+	for i, h := range models.HealthStatuses {
+		if h == health {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkProjectHealth 校验并规范化项目健康度，返回逐字段的校验错误
+// 取值为 green / amber / red 或 null，非绿色时必须填写原因
+// 更新时（before 不为 nil）健康度变为非绿色需同时提交新的原因，变为绿色且未提交原因时清空旧原因
+func checkProjectHealth(p, before *models.Project, reasonPatched bool) map[string]string {
+	fieldErrors := make(map[string]string)
+	if p.Health != nil && *p.Health == "" {
+		p.Health = nil
+	}
+	if p.Health != nil && healthRank(*p.Health) < 0 {
+		fieldErrors["health"] = "must be one of " + strings.Join(models.HealthStatuses, ", ")
+		return fieldErrors
+	}
+	if p.HealthReason != nil {
+		reason := strings.TrimSpace(*p.HealthReason)
+		p.HealthReason = &reason
+		if reason == "" {
+			p.HealthReason = nil
+		}
+	}
+
+	changed := before != nil && derefString(before.Health) != derefString(p.Health)
+	if changed && !reasonPatched {
+		if p.Health == nil || *p.Health == "green" {
+			p.HealthReason = nil
+		} else {
+			fieldErrors["healthReason"] = "is required when health changes to " + *p.Health
+			return fieldErrors
+		}
+	}
+
+	if p.Health != nil && *p.Health != "green" && p.HealthReason == nil {
+		fieldErrors["healthReason"] = "is required when health is " + *p.Health
+	}
+	return fieldErrors
+}
+
+// healthChanged 判断健康度或其原因是否有变化
+func healthChanged(before, after *models.Project) bool {
+	return derefString(before.Health) != derefString(after.Health) ||
+		derefString(before.HealthReason) != derefString(after.HealthReason)
+}
+
+// insertHealthChange 记录一次健康度变更
+func insertHealthChange(tx *sql.Tx, p *models.Project, userID string, now time.Time) error {
+	var changedBy *string
+	if userID != "" {
+		changedBy = &userID
+	}
+	_, err := tx.Exec(`
+		INSERT INTO project_health_history (id, project_id, health, reason, changed_by, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		newID("health"), p.ID, p.Health, p.HealthReason, changedBy, now)
+	return err
+}
+
+// snapshotWeeklyHealth 周会滚动时记录各项目当前的健康度，作为下周比较的基准
+func snapshotWeeklyHealth(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		UPDATE projects
+		SET last_week_health = health, version = version + 1
+		WHERE deleted_at IS NULL AND COALESCE(health, '') <> COALESCE(last_week_health, '')`)
+	return err
+}
+
+// GetProjectHealthHistory 获取项目健康度的变更历史，最近的在前
+func (h *Handler) GetProjectHealthHistory(c *gin.Context) {
+	projectID := c.Param("projectId")
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	rows, err := h.db.Query(`
+		SELECT id, health, reason, changed_by, changed_at
+		FROM project_health_history
+		WHERE project_id = $1
+		ORDER BY changed_at DESC, id DESC`, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	history := []models.HealthChange{}
+	for rows.Next() {
+		var change models.HealthChange
+		if err := rows.Scan(&change.ID, &change.Health, &change.Reason, &change.ChangedBy, &change.ChangedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		history = append(history, change)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetHealthWorsenedReport 自上次周会滚动以来健康度变差的项目（上次未评估视为 green）
+// 不包含已删除和已归档的项目，red 在前
+func (h *Handler) GetHealthWorsenedReport(c *gin.Context) {
+	rows, err := h.db.Query(`
+		SELECT p.id, p.name, p.status, p.last_week_health, p.health, p.health_reason,
+			(SELECT MAX(hh.changed_at) FROM project_health_history hh WHERE hh.project_id = p.id)
+		FROM projects p
+		WHERE p.deleted_at IS NULL AND p.archived_at IS NULL AND p.health IN ('amber', 'red')`)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	worsened := []models.HealthWorsening{}
+	for rows.Next() {
+		var w models.HealthWorsening
+		if err := rows.Scan(&w.ProjectID, &w.ProjectName, &w.Status, &w.PreviousHealth, &w.Health, &w.HealthReason, &w.HealthChangedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		previous := "green"
+		if w.PreviousHealth != nil {
+			previous = *w.PreviousHealth
+		}
+		if healthRank(w.Health) > healthRank(previous) {
+			worsened = append(worsened, w)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	sort.SliceStable(worsened, func(i, j int) bool {
+		if worsened[i].Health != worsened[j].Health {
+			return healthRank(worsened[i].Health) > healthRank(worsened[j].Health)
+		}
+		return worsened[i].ProjectName < worsened[j].ProjectName
+	})
+	c.JSON(http.StatusOK, worsened)
+}
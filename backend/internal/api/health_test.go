@@ -0,0 +1,101 @@
+package api
+
+import (
+	"testing"
+
+	"project-management-backend/internal/models"
+)
+
+func TestHealthRank(t *testing.T) {
+	tests := map[string]int{"green": 0, "amber": 1, "red": 2, "": -1, "blue": -1}
+	for health, want := range tests {
+		if got := healthRank(health); got != want {
+			t.Errorf("healthRank(%q) = %d, want %d", health, got, want)
+		}
+	}
+}
+
+func TestCheckProjectHealth(t *testing.T) {
+	s := func(v string) *string { return &v }
+	tests := []struct {
+		name          string
+		health        *string
+		reason        *string
+		before        *models.Project
+		reasonPatched bool
+		wantErrField  string
+		wantHealth    *string
+		wantReason    *string
+	}{
+		{name: "unset", health: nil},
+		{name: "empty health is cleared", health: s(""), wantHealth: nil},
+		{name: "invalid health", health: s("blue"), wantErrField: "health"},
+		{name: "green without reason", health: s("green"), wantHealth: s("green")},
+		{name: "amber without reason", health: s("amber"), wantErrField: "healthReason"},
+		{name: "blank reason", health: s("red"), reason: s("  "), wantErrField: "healthReason"},
+		{name: "red with reason", health: s("red"), reason: s(" late "), wantHealth: s("red"), wantReason: s("late")},
+		{
+			name: "worsens without new reason", health: s("amber"), reason: s("old"),
+			before: &models.Project{Health: s("green"), HealthReason: s("old")}, wantErrField: "healthReason",
+		},
+		{
+			name: "worsens with new reason", health: s("red"), reason: s("blocked"), reasonPatched: true,
+			before: &models.Project{Health: s("amber"), HealthReason: s("late")}, wantHealth: s("red"), wantReason: s("blocked"),
+		},
+		{
+			name: "recovers clears reason", health: s("green"), reason: s("late"),
+			before: &models.Project{Health: s("amber"), HealthReason: s("late")}, wantHealth: s("green"),
+		},
+		{
+			name: "unchanged health keeps reason", health: s("amber"), reason: s("late"),
+			before: &models.Project{Health: s("amber"), HealthReason: s("late")}, wantHealth: s("amber"), wantReason: s("late"),
+		},
+		{
+			name: "reason removed while amber", health: s("amber"), reason: nil, reasonPatched: true,
+			before: &models.Project{Health: s("amber"), HealthReason: s("late")}, wantErrField: "healthReason",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &models.Project{Health: tt.health, HealthReason: tt.reason}
+			fieldErrors := checkProjectHealth(p, tt.before, tt.reasonPatched)
+			if tt.wantErrField != "" {
+				if _, ok := fieldErrors[tt.wantErrField]; !ok {
+					t.Fatalf("expected error on %s, got %v", tt.wantErrField, fieldErrors)
+				}
+				return
+			}
+			if len(fieldErrors) > 0 {
+				t.Fatalf("unexpected errors: %v", fieldErrors)
+			}
+			if derefString(p.Health) != derefString(tt.wantHealth) {
+				t.Errorf("health = %q, want %q", derefString(p.Health), derefString(tt.wantHealth))
+			}
+			if derefString(p.HealthReason) != derefString(tt.wantReason) {
+				t.Errorf("reason = %q, want %q", derefString(p.HealthReason), derefString(tt.wantReason))
+			}
+		})
+	}
+}
+
+func TestHealthChanged(t *testing.T) {
+	s := func(v string) *string { return &v }
+	tests := []struct {
+		name          string
+		before, after models.Project
+		want          bool
+	}{
+		{"both unset", models.Project{}, models.Project{}, false},
+		{"set", models.Project{}, models.Project{Health: s("green")}, true},
+		{"same", models.Project{Health: s("amber"), HealthReason: s("late")}, models.Project{Health: s("amber"), HealthReason: s("late")}, false},
+		{"reason only", models.Project{Health: s("amber"), HealthReason: s("late")}, models.Project{Health: s("amber"), HealthReason: s("blocked")}, true},
+		{"empty equals unset", models.Project{Health: s("")}, models.Project{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := healthChanged(&tt.before, &tt.after); got != tt.want {
+				t.Errorf("healthChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package api
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// idSeq 保证同一纳秒内生成的ID不重复
+var idSeq uint64
+
+// newID 生成带前缀的ID，格式为 <prefix>_<纳秒时间戳>_<序号>
+func newID(prefix string) string {
+	seq := atomic.AddUint64(&idSeq, 1)
+	return prefix + "_" + strconv.FormatInt(time.Now().UnixNano(), 10) + "_" + strconv.FormatUint(seq, 10)
+}
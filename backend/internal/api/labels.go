@@ -0,0 +1,353 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// labelColorPattern 标签颜色格式 #RRGGBB
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// labelColumns 构成标签的 labels 列，与 scanLabel 的扫描顺序一致
+const labelColumns = "l.id, l.name, l.color, l.created_at"
+
+// scanLabel 扫描一行标签数据，extra 用于接收 labelColumns 之后的附加列
+func scanLabel(row rowScanner, extra ...interface{}) (models.Label, error) {
+	var label models.Label
+	err := row.Scan(append([]interface{}{&label.ID, &label.Name, &label.Color, &label.CreatedAt}, extra...)...)
+	return label, err
+}
+
+// labelNameTaken 判断标签名称是否已被其他标签使用
+func (h *Handler) labelNameTaken(name, excludeID string) (bool, error) {
+	var taken bool
+	err := h.db.QueryRow("SELECT EXISTS (SELECT 1 FROM labels WHERE name = $1 AND id <> $2)", name, excludeID).Scan(&taken)
+	return taken, err
+}
+
+// isUniqueViolation 判断错误是否为唯一约束冲突（PostgreSQL 23505 或 SQLite UNIQUE constraint）
+// 用于并发写入同一唯一值时返回 409 而不是 500
+func isUniqueViolation(err error) bool {
+	if pqErr, ok := err.(*pq.Error); ok {
+		return pqErr.Code == "23505"
+	}
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// loadProjectLabels 批量加载项目的标签，按名称排序
+func (h *Handler) loadProjectLabels(projects []models.Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+
+	projectIDs := make([]string, len(projects))
+	for i, project := range projects {
+		projectIDs[i] = project.ID
+	}
+
+	rows, err := h.db.Query(`
+		SELECT `+labelColumns+`, pl.project_id
+		FROM project_labels pl
+		JOIN labels l ON l.id = pl.label_id
+		WHERE pl.project_id = ANY($1)
+		ORDER BY l.name, l.id`, pq.Array(projectIDs))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	labelsByProject := make(map[string][]models.Label)
+	for rows.Next() {
+		var projectID string
+		label, err := scanLabel(rows, &projectID)
+		if err != nil {
+			return err
+		}
+		labelsByProject[projectID] = append(labelsByProject[projectID], label)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range projects {
+		projects[i].Labels = labelsByProject[projects[i].ID]
+		if projects[i].Labels == nil {
+			projects[i].Labels = []models.Label{}
+		}
+	}
+	return nil
+}
+
+// respondProjectLabels 返回项目当前的标签
+func (h *Handler) respondProjectLabels(c *gin.Context, projectID string) {
+	projects := []models.Project{{ID: projectID}}
+	if err := h.loadProjectLabels(projects); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, projects[0].Labels)
+}
+
+// GetLabels 获取标签目录
+func (h *Handler) GetLabels(c *gin.Context) {
+	rows, err := h.db.Query("SELECT " + labelColumns + " FROM labels l ORDER BY l.name, l.id")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	labels := []models.Label{}
+	for rows.Next() {
+		label, err := scanLabel(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		labels = append(labels, label)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, labels)
+}
+
+// CreateLabel 新增标签（管理员），名称唯一，颜色为 #RRGGBB
+func (h *Handler) CreateLabel(c *gin.Context) {
+	var req struct {
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	if !labelColorPattern.MatchString(req.Color) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "color must be in #RRGGBB format"})
+		return
+	}
+
+	label := models.Label{ID: newID("label"), Name: req.Name, Color: strings.ToLower(req.Color)}
+	err := h.db.QueryRow(`
+		INSERT INTO labels (id, name, color)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING created_at`,
+		label.ID, label.Name, label.Color).Scan(&label.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows || isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Label name already exists"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, label)
+}
+
+// UpdateLabel 修改标签名称或颜色（管理员）
+func (h *Handler) UpdateLabel(c *gin.Context) {
+	labelID := c.Param("labelId")
+
+	var req struct {
+		Name  *string `json:"name"`
+		Color *string `json:"color"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	label, err := scanLabel(h.db.QueryRow("SELECT "+labelColumns+" FROM labels l WHERE l.id = $1", labelID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Label not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if req.Name != nil {
+		label.Name = strings.TrimSpace(*req.Name)
+		if label.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name cannot be empty"})
+			return
+		}
+		taken, err := h.labelNameTaken(label.Name, labelID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if taken {
+			c.JSON(http.StatusConflict, gin.H{"error": "Label name already exists"})
+			return
+		}
+	}
+	if req.Color != nil {
+		if !labelColorPattern.MatchString(*req.Color) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "color must be in #RRGGBB format"})
+			return
+		}
+		label.Color = strings.ToLower(*req.Color)
+	}
+
+	// 并发改为同一名称时由唯一约束兜底
+	if _, err := h.db.Exec("UPDATE labels SET name = $2, color = $3 WHERE id = $1", labelID, label.Name, label.Color); err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Label name already exists"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, label)
+}
+
+// DeleteLabel 删除标签（管理员），同时移除所有项目上的该标签
+func (h *Handler) DeleteLabel(c *gin.Context) {
+	labelID := c.Param("labelId")
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM labels WHERE id = $1", labelID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Label not found"})
+		return
+	}
+	if _, err := tx.Exec("DELETE FROM project_labels WHERE label_id = $1", labelID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// SetProjectLabels 整体替换项目的标签（labelIds 为空数组时清空）
+func (h *Handler) SetProjectLabels(c *gin.Context) {
+	projectID := c.Param("projectId")
+
+	var req struct {
+		LabelIDs []string `json:"labelIds"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var labelIDs []string
+	for _, labelID := range req.LabelIDs {
+		labelIDs = appendUnique(labelIDs, labelID)
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	if !h.checkProjectExists(c, tx, projectID) {
+		return
+	}
+
+	if len(labelIDs) > 0 {
+		var found int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM labels WHERE id = ANY($1)", pq.Array(labelIDs)).Scan(&found); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if found != len(labelIDs) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown label in labelIds"})
+			return
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM project_labels WHERE project_id = $1", projectID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, labelID := range labelIDs {
+		if _, err := tx.Exec("INSERT INTO project_labels (project_id, label_id) VALUES ($1, $2)", projectID, labelID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save labels: " + err.Error()})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+	h.respondProjectLabels(c, projectID)
+}
+
+// AddProjectLabel 为项目添加一个标签，已存在时不做修改
+func (h *Handler) AddProjectLabel(c *gin.Context) {
+	projectID := c.Param("projectId")
+	labelID := c.Param("labelId")
+
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+	var exists bool
+	if err := h.db.QueryRow("SELECT EXISTS (SELECT 1 FROM labels WHERE id = $1)", labelID).Scan(&exists); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Label not found"})
+		return
+	}
+
+	_, err := h.db.Exec(`
+		INSERT INTO project_labels (project_id, label_id)
+		VALUES ($1, $2)
+		ON CONFLICT (project_id, label_id) DO NOTHING`, projectID, labelID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save labels: " + err.Error()})
+		return
+	}
+	h.respondProjectLabels(c, projectID)
+}
+
+// RemoveProjectLabel 移除项目的一个标签
+func (h *Handler) RemoveProjectLabel(c *gin.Context) {
+	projectID := c.Param("projectId")
+	labelID := c.Param("labelId")
+
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+	if _, err := h.db.Exec("DELETE FROM project_labels WHERE project_id = $1 AND label_id = $2", projectID, labelID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.respondProjectLabels(c, projectID)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"postgres unique violation", &pq.Error{Code: "23505"}, true},
+		{"postgres other error", &pq.Error{Code: "23503"}, false},
+		{"sqlite unique constraint", errors.New("UNIQUE constraint failed: labels.name"), true},
+		{"other error", errors.New("connection refused"), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		if got := isUniqueViolation(tt.err); got != tt.want {
+			t.Errorf("%s: isUniqueViolation = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLabelColorPattern(t *testing.T) {
+	for color, want := range map[string]bool{
+		"#1a2B3c": true,
+		"1a2b3c":  false,
+		"#1a2b3":  false,
+		"#1a2b3g": false,
+	} {
+		if got := labelColorPattern.MatchString(color); got != want {
+			t.Errorf("labelColorPattern(%q) = %v, want %v", color, got, want)
+		}
+	}
+}
+
+func TestApplyFiltersLabels(t *testing.T) {
+	q, err := parseProjectListQuery(newQueryContext("labelId=l1,l2&labelId=l3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &sqlBuilder{}
+	q.applyFilters(b)
+	if !strings.Contains(b.whereClause(), "pl.label_id = ANY($1)") {
+		t.Errorf("where clause missing label filter: %s", b.whereClause())
+	}
+	if len(b.args) != 1 || !reflect.DeepEqual(b.args[0], pq.Array([]string{"l1", "l2", "l3"})) {
+		t.Errorf("args = %#v", b.args)
+	}
+}
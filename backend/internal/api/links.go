@@ -0,0 +1,280 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"project-management-backend/internal/middleware"
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// linkColumns 构成链接的 project_links 列，与 scanLink 的扫描顺序一致
+const linkColumns = "id, kind, title, url, created_by, created_at"
+
+// scanLink 扫描一行链接数据，extra 用于接收 linkColumns 之后的附加列
+func scanLink(row rowScanner, extra ...interface{}) (models.ProjectLink, error) {
+	var link models.ProjectLink
+	err := row.Scan(append([]interface{}{&link.ID, &link.Kind, &link.Title, &link.URL, &link.CreatedBy, &link.CreatedAt}, extra...)...)
+	return link, err
+}
+
+// isLinkKind 判断是否为支持的链接类型
+func isLinkKind(kind string) bool {
+	for _, k := range models.LinkKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// linkPatchers 链接支持的 merge patch 字段
+var linkPatchers = map[string]func(link *models.ProjectLink, raw json.RawMessage) error{
+	"kind": func(link *models.ProjectLink, raw json.RawMessage) error {
+		if err := patchRequiredString(&link.Kind, raw); err != nil {
+			return err
+		}
+		if !isLinkKind(link.Kind) {
+			return fmt.Errorf("must be one of %s", strings.Join(models.LinkKinds, ", "))
+		}
+		return nil
+	},
+	"title": func(link *models.ProjectLink, raw json.RawMessage) error {
+		var title *string
+		if err := patchOptionalString(&title, raw); err != nil {
+			return err
+		}
+		link.Title = ""
+		if title != nil {
+			link.Title = strings.TrimSpace(*title)
+		}
+		return nil
+	},
+	"url": func(link *models.ProjectLink, raw json.RawMessage) error {
+		if err := patchRequiredString(&link.URL, raw); err != nil {
+			return err
+		}
+		link.URL = strings.TrimSpace(link.URL)
+		u, err := url.Parse(link.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("must be an absolute http or https URL")
+		}
+		return nil
+	},
+}
+
+// linkKindsOf 返回链接中出现的类型，按 models.LinkKinds 的顺序排列
+func linkKindsOf(links []models.ProjectLink) []string {
+	present := make(map[string]bool)
+	for _, link := range links {
+		present[link.Kind] = true
+	}
+	kinds := []string{}
+	for _, kind := range models.LinkKinds {
+		if present[kind] {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// loadProjectLinks 批量加载项目的链接并生成 linkKinds 徽标
+func (h *Handler) loadProjectLinks(projects []models.Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+
+	projectIDs := make([]string, len(projects))
+	for i, project := range projects {
+		projectIDs[i] = project.ID
+	}
+
+	rows, err := h.db.Query(`
+		SELECT `+linkColumns+`, project_id
+		FROM project_links
+		WHERE project_id = ANY($1)
+		ORDER BY project_id, created_at, id`, pq.Array(projectIDs))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	linksByProject := make(map[string][]models.ProjectLink)
+	for rows.Next() {
+		var projectID string
+		link, err := scanLink(rows, &projectID)
+		if err != nil {
+			return err
+		}
+		linksByProject[projectID] = append(linksByProject[projectID], link)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range projects {
+		projects[i].Links = linksByProject[projects[i].ID]
+		if projects[i].Links == nil {
+			projects[i].Links = []models.ProjectLink{}
+		}
+		projects[i].LinkKinds = linkKindsOf(projects[i].Links)
+	}
+	return nil
+}
+
+// loadLink 读取项目的单个链接
+func (h *Handler) loadLink(projectID, linkID string) (models.ProjectLink, error) {
+	return scanLink(h.db.QueryRow(
+		"SELECT "+linkColumns+" FROM project_links WHERE id = $1 AND project_id = $2",
+		linkID, projectID))
+}
+
+// linkURLTaken 判断项目中是否已有相同URL的其他链接
+func (h *Handler) linkURLTaken(projectID, linkURL, excludeID string) (bool, error) {
+	var taken bool
+	err := h.db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM project_links WHERE project_id = $1 AND url = $2 AND id <> $3)",
+		projectID, linkURL, excludeID).Scan(&taken)
+	return taken, err
+}
+
+// GetProjectLinks 获取项目的链接，按创建时间排序
+func (h *Handler) GetProjectLinks(c *gin.Context) {
+	projectID := c.Param("projectId")
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	projects := []models.Project{{ID: projectID}}
+	if err := h.loadProjectLinks(projects); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, projects[0].Links)
+}
+
+// CreateProjectLink 新增链接，kind 与 url 必填，同一项目内 url 不能重复
+func (h *Handler) CreateProjectLink(c *gin.Context) {
+	projectID := c.Param("projectId")
+	patch, ok := bindMergePatch(c, "Link")
+	if !ok {
+		return
+	}
+
+	link := models.ProjectLink{ID: newID("link")}
+	fieldErrors := applyPatch(&link, linkPatchers, patch)
+	if _, ok := patch["kind"]; !ok {
+		fieldErrors["kind"] = "is required"
+	}
+	if _, ok := patch["url"]; !ok {
+		fieldErrors["url"] = "is required"
+	}
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid link", "fields": fieldErrors})
+		return
+	}
+
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+	if taken, err := h.linkURLTaken(projectID, link.URL, link.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "Link already exists"})
+		return
+	}
+
+	var createdBy *string
+	if userID, _, _, _ := middleware.GetCurrentUser(c); userID != "" {
+		createdBy = &userID
+	}
+	_, err := h.db.Exec(`
+		INSERT INTO project_links (id, project_id, kind, title, url, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		link.ID, projectID, link.Kind, link.Title, link.URL, createdBy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save link: " + err.Error()})
+		return
+	}
+
+	saved, err := h.loadLink(projectID, link.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, saved)
+}
+
+// UpdateProjectLink 更新链接（JSON Merge Patch）
+func (h *Handler) UpdateProjectLink(c *gin.Context) {
+	projectID := c.Param("projectId")
+	linkID := c.Param("linkId")
+	patch, ok := bindMergePatch(c, "Link")
+	if !ok {
+		return
+	}
+
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	link, err := h.loadLink(projectID, linkID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if fieldErrors := applyPatch(&link, linkPatchers, patch); len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid link", "fields": fieldErrors})
+		return
+	}
+	if taken, err := h.linkURLTaken(projectID, link.URL, linkID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "Link already exists"})
+		return
+	}
+
+	_, err = h.db.Exec(
+		"UPDATE project_links SET kind = $1, title = $2, url = $3 WHERE id = $4 AND project_id = $5",
+		link.Kind, link.Title, link.URL, linkID, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save link: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, link)
+}
+
+// DeleteProjectLink 删除链接
+func (h *Handler) DeleteProjectLink(c *gin.Context) {
+	projectID := c.Param("projectId")
+	linkID := c.Param("linkId")
+
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	result, err := h.db.Exec("DELETE FROM project_links WHERE id = $1 AND project_id = $2", linkID, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
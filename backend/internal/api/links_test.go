@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"project-management-backend/internal/models"
+)
+
+func TestApplyLinkPatchURL(t *testing.T) {
+	tests := []struct {
+		raw     string
+		wantURL string
+		wantErr bool
+	}{
+		{`"https://example.com/prd"`, "https://example.com/prd", false},
+		{`"  http://example.com  "`, "http://example.com", false},
+		{`"ftp://example.com/file"`, "", true},
+		{`"example.com/prd"`, "", true},
+		{`"https://"`, "", true},
+		{`"javascript:alert(1)"`, "", true},
+		{`""`, "", true},
+		{`null`, "", true},
+		{`42`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			var link models.ProjectLink
+			fieldErrors := applyPatch(&link, linkPatchers, map[string]json.RawMessage{"url": json.RawMessage(tt.raw)})
+			if tt.wantErr {
+				if _, ok := fieldErrors["url"]; !ok {
+					t.Fatalf("expected url error, got %v", fieldErrors)
+				}
+				return
+			}
+			if len(fieldErrors) > 0 {
+				t.Fatalf("unexpected errors: %v", fieldErrors)
+			}
+			if link.URL != tt.wantURL {
+				t.Errorf("url = %q, want %q", link.URL, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestApplyLinkPatchKind(t *testing.T) {
+	var link models.ProjectLink
+	if fieldErrors := applyPatch(&link, linkPatchers, map[string]json.RawMessage{"kind": json.RawMessage(`"design"`)}); len(fieldErrors) > 0 {
+		t.Fatalf("unexpected errors: %v", fieldErrors)
+	}
+	if fieldErrors := applyPatch(&link, linkPatchers, map[string]json.RawMessage{"kind": json.RawMessage(`"wiki"`)}); fieldErrors["kind"] == "" {
+		t.Error("expected kind error for unknown kind")
+	}
+}
+
+func TestLinkKindsOf(t *testing.T) {
+	links := []models.ProjectLink{{Kind: "other"}, {Kind: "prd"}, {Kind: "other"}, {Kind: "design"}}
+	got := linkKindsOf(links)
+	want := []string{"prd", "design", "other"}
+	if len(got) != len(want) {
+		t.Fatalf("linkKindsOf = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("linkKindsOf = %v, want %v", got, want)
+		}
+	}
+}
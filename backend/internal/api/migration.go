@@ -43,14 +43,44 @@ func (h *Handler) MigrateInitialData(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Initial data migration completed successfully"})
 }
 
+// clearTables 清空迁移涉及的表，关联项目的子表先于 projects 清空，附件文件一并删除
 func (h *Handler) clearTables() error {
-	tables := []string{"projects", "okr_sets", "users"}
+	var attachmentIDs []string
+	rows, err := h.db.Query("SELECT id FROM project_attachments")
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		attachmentIDs = append(attachmentIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	tables := []string{
+		"time_slots", "project_dependencies", "project_milestones", "project_tasks", "project_labels",
+		"project_attachments", "project_links", "project_risks", "project_health_history",
+		"project_effort_estimates",
+		"projects", "okr_sets", "users",
+	}
 	for _, table := range tables {
 		_, err := h.db.Exec("DELETE FROM " + table)
 		if err != nil {
 			return err
 		}
 	}
+
+	for _, id := range attachmentIDs {
+		if err := h.attachments.Storage.Delete(id); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
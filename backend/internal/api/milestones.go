@@ -0,0 +1,280 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// milestoneColumns 构成里程碑的 project_milestones 列，与 scanMilestone 的扫描顺序一致
+const milestoneColumns = "id, name, planned_date, actual_date, owner_id, done, created_at, updated_at"
+
+// scanMilestone 扫描一行里程碑数据，日期统一为 YYYY-MM-DD
+// extra 用于接收 milestoneColumns 之后的附加列
+func scanMilestone(row rowScanner, extra ...interface{}) (models.Milestone, error) {
+	var m models.Milestone
+	var plannedDate string
+	var actualDate *string
+	dest := append([]interface{}{
+		&m.ID, &m.Name, &plannedDate, &actualDate, &m.OwnerID, &m.Done, &m.CreatedAt, &m.UpdatedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return m, err
+	}
+	m.PlannedDate = derefDate(&plannedDate)
+	if actualDate != nil {
+		date := derefDate(actualDate)
+		m.ActualDate = &date
+	}
+	return m, nil
+}
+
+// milestonePatchers 里程碑支持的 merge patch 字段
+var milestonePatchers = map[string]func(m *models.Milestone, raw json.RawMessage) error{
+	"name": func(m *models.Milestone, raw json.RawMessage) error {
+		if err := patchRequiredString(&m.Name, raw); err != nil {
+			return err
+		}
+		m.Name = strings.TrimSpace(m.Name)
+		return nil
+	},
+	"plannedDate": func(m *models.Milestone, raw json.RawMessage) error {
+		var date *string
+		if err := patchOptionalDate(&date, raw); err != nil {
+			return err
+		}
+		if date == nil {
+			return fmt.Errorf("is required and cannot be cleared")
+		}
+		m.PlannedDate = *date
+		return nil
+	},
+	"actualDate": func(m *models.Milestone, raw json.RawMessage) error {
+		return patchOptionalDate(&m.ActualDate, raw)
+	},
+	"ownerId": func(m *models.Milestone, raw json.RawMessage) error {
+		return patchOptionalID(&m.OwnerID, raw)
+	},
+	"done": func(m *models.Milestone, raw json.RawMessage) error {
+		if err := json.Unmarshal(raw, &m.Done); err != nil || isJSONNull(raw) {
+			return fmt.Errorf("must be a boolean")
+		}
+		return nil
+	},
+}
+
+// summarizeMilestones 汇总按计划日期排序的里程碑：计划日期早于 today 且未完成的为逾期，
+// 其余未完成中最早的一个为下一个里程碑
+func summarizeMilestones(milestones []models.Milestone, today string) *models.MilestoneSummary {
+	summary := &models.MilestoneSummary{Total: len(milestones), Overdue: []models.Milestone{}}
+	for i, m := range milestones {
+		switch {
+		case m.Done:
+			summary.Done++
+		case m.PlannedDate < today:
+			summary.Overdue = append(summary.Overdue, m)
+		case summary.Next == nil:
+			summary.Next = &milestones[i]
+		}
+	}
+	return summary
+}
+
+// loadMilestoneSummaries 批量加载项目的里程碑并生成概览
+func (h *Handler) loadMilestoneSummaries(projects []models.Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+
+	projectIDs := make([]string, len(projects))
+	for i, project := range projects {
+		projectIDs[i] = project.ID
+	}
+
+	rows, err := h.db.Query(`
+		SELECT `+milestoneColumns+`, project_id
+		FROM project_milestones
+		WHERE project_id = ANY($1)
+		ORDER BY project_id, planned_date, id`, pq.Array(projectIDs))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	milestonesByProject := make(map[string][]models.Milestone)
+	for rows.Next() {
+		var projectID string
+		m, err := scanMilestone(rows, &projectID)
+		if err != nil {
+			return err
+		}
+		milestonesByProject[projectID] = append(milestonesByProject[projectID], m)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	today := time.Now().Format("2006-01-02")
+	for i := range projects {
+		projects[i].MilestoneSummary = summarizeMilestones(milestonesByProject[projects[i].ID], today)
+	}
+	return nil
+}
+
+// loadMilestone 读取项目的单个里程碑
+func (h *Handler) loadMilestone(projectID, milestoneID string) (models.Milestone, error) {
+	return scanMilestone(h.db.QueryRow(
+		"SELECT "+milestoneColumns+" FROM project_milestones WHERE id = $1 AND project_id = $2",
+		milestoneID, projectID))
+}
+
+// GetProjectMilestones 获取项目的里程碑，按计划日期排序
+func (h *Handler) GetProjectMilestones(c *gin.Context) {
+	projectID := c.Param("projectId")
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	rows, err := h.db.Query(
+		"SELECT "+milestoneColumns+" FROM project_milestones WHERE project_id = $1 ORDER BY planned_date, id",
+		projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	milestones := []models.Milestone{}
+	for rows.Next() {
+		m, err := scanMilestone(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		milestones = append(milestones, m)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, milestones)
+}
+
+// CreateProjectMilestone 新增里程碑，name 与 plannedDate 必填
+func (h *Handler) CreateProjectMilestone(c *gin.Context) {
+	projectID := c.Param("projectId")
+	patch, ok := bindMergePatch(c, "Milestone")
+	if !ok {
+		return
+	}
+
+	milestone := models.Milestone{ID: newID("ms")}
+	fieldErrors := applyPatch(&milestone, milestonePatchers, patch)
+	if _, ok := patch["name"]; !ok {
+		fieldErrors["name"] = "is required"
+	}
+	if _, ok := patch["plannedDate"]; !ok {
+		fieldErrors["plannedDate"] = "is required"
+	}
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid milestone", "fields": fieldErrors})
+		return
+	}
+
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	_, err := h.db.Exec(`
+		INSERT INTO project_milestones (id, project_id, name, planned_date, actual_date, owner_id, done)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		milestone.ID, projectID, milestone.Name, milestone.PlannedDate, milestone.ActualDate, milestone.OwnerID, milestone.Done)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save milestone: " + err.Error()})
+		return
+	}
+
+	saved, err := h.loadMilestone(projectID, milestone.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, saved)
+}
+
+// UpdateProjectMilestone 更新里程碑（JSON Merge Patch）
+func (h *Handler) UpdateProjectMilestone(c *gin.Context) {
+	projectID := c.Param("projectId")
+	milestoneID := c.Param("milestoneId")
+	patch, ok := bindMergePatch(c, "Milestone")
+	if !ok {
+		return
+	}
+
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	milestone, err := h.loadMilestone(projectID, milestoneID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Milestone not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if fieldErrors := applyPatch(&milestone, milestonePatchers, patch); len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid milestone", "fields": fieldErrors})
+		return
+	}
+
+	_, err = h.db.Exec(`
+		UPDATE project_milestones
+		SET name = $1, planned_date = $2, actual_date = $3, owner_id = $4, done = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6 AND project_id = $7`,
+		milestone.Name, milestone.PlannedDate, milestone.ActualDate, milestone.OwnerID, milestone.Done,
+		milestoneID, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save milestone: " + err.Error()})
+		return
+	}
+
+	saved, err := h.loadMilestone(projectID, milestoneID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, saved)
+}
+
+// DeleteProjectMilestone 删除里程碑
+func (h *Handler) DeleteProjectMilestone(c *gin.Context) {
+	projectID := c.Param("projectId")
+	milestoneID := c.Param("milestoneId")
+
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	result, err := h.db.Exec("DELETE FROM project_milestones WHERE id = $1 AND project_id = $2", milestoneID, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Milestone not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"project-management-backend/internal/models"
+)
+
+func TestSummarizeMilestones(t *testing.T) {
+	milestones := []models.Milestone{
+		{ID: "done", PlannedDate: "2026-10-01", Done: true},
+		{ID: "overdue", PlannedDate: "2026-10-16"},
+		{ID: "today", PlannedDate: "2026-10-17"},
+		{ID: "later", PlannedDate: "2026-10-20"},
+	}
+	summary := summarizeMilestones(milestones, "2026-10-17")
+
+	if summary.Total != 4 || summary.Done != 1 {
+		t.Errorf("total = %d, done = %d", summary.Total, summary.Done)
+	}
+	// 计划日期为今天的里程碑尚未逾期，是下一个里程碑
+	if len(summary.Overdue) != 1 || summary.Overdue[0].ID != "overdue" {
+		t.Errorf("overdue = %+v", summary.Overdue)
+	}
+	if summary.Next == nil || summary.Next.ID != "today" {
+		t.Errorf("next = %+v", summary.Next)
+	}
+
+	empty := summarizeMilestones(nil, "2026-10-17")
+	if empty.Total != 0 || empty.Next != nil || empty.Overdue == nil || len(empty.Overdue) != 0 {
+		t.Errorf("empty summary = %+v", empty)
+	}
+}
+
+func TestApplyMilestonePatch(t *testing.T) {
+	s := func(v string) *string { return &v }
+	tests := []struct {
+		name       string
+		patch      string
+		want       models.Milestone
+		wantErrors []string
+	}{
+		{name: "trim name and normalize dates", patch: `{"name":"  上线  ","plannedDate":"2026-11-01T00:00:00Z","actualDate":"2026-11-02"}`,
+			want: models.Milestone{Name: "上线", PlannedDate: "2026-11-01", ActualDate: s("2026-11-02"), OwnerID: s("u1")}},
+		{name: "null clears optional fields", patch: `{"actualDate":null,"ownerId":null}`,
+			want: models.Milestone{Name: "评审", PlannedDate: "2026-10-20"}},
+		{name: "blank owner clears owner", patch: `{"ownerId":" ","done":true}`,
+			want: models.Milestone{Name: "评审", PlannedDate: "2026-10-20", ActualDate: s("2026-10-21"), Done: true}},
+		{name: "required fields cannot be cleared", patch: `{"name":null,"plannedDate":null,"done":null}`,
+			wantErrors: []string{"name", "plannedDate", "done"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatal(err)
+			}
+			m := models.Milestone{Name: "评审", PlannedDate: "2026-10-20", ActualDate: s("2026-10-21"), OwnerID: s("u1")}
+			fieldErrors := applyPatch(&m, milestonePatchers, patch)
+			if len(tt.wantErrors) > 0 {
+				for _, field := range tt.wantErrors {
+					if _, ok := fieldErrors[field]; !ok {
+						t.Errorf("missing error for %s: %v", field, fieldErrors)
+					}
+				}
+				return
+			}
+			if len(fieldErrors) > 0 {
+				t.Fatalf("unexpected errors %v", fieldErrors)
+			}
+			if !reflect.DeepEqual(m, tt.want) {
+				t.Errorf("milestone = %+v, want %+v", m, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// projectFieldPatcher 将单个字段的补丁值应用到项目上，校验失败时返回错误
+type projectFieldPatcher func(p *models.Project, raw json.RawMessage) error
+
+// projectPatchers 支持 JSON Merge Patch (RFC 7396) 的项目字段
+// 显式的 null 表示清空该字段；必填字段不允许清空
+var projectPatchers = map[string]projectFieldPatcher{
+	"name": func(p *models.Project, raw json.RawMessage) error {
+		return patchRequiredString(&p.Name, raw)
+	},
+	"priority": func(p *models.Project, raw json.RawMessage) error {
+		return patchRequiredString(&p.Priority, raw)
+	},
+	"status": func(p *models.Project, raw json.RawMessage) error {
+		return patchRequiredString(&p.Status, raw)
+	},
+	"businessProblem": func(p *models.Project, raw json.RawMessage) error {
+		return patchOptionalString(&p.BusinessProblem, raw)
+	},
+	"weeklyUpdate": func(p *models.Project, raw json.RawMessage) error {
+		return patchOptionalString(&p.WeeklyUpdate, raw)
+	},
+	"lastWeekUpdate": func(p *models.Project, raw json.RawMessage) error {
+		return patchOptionalString(&p.LastWeekUpdate, raw)
+	},
+	// health 与 healthReason 的组合由 checkProjectHealth 校验
+	"health": func(p *models.Project, raw json.RawMessage) error {
+		return patchOptionalString(&p.Health, raw)
+	},
+	"healthReason": func(p *models.Project, raw json.RawMessage) error {
+		return patchOptionalString(&p.HealthReason, raw)
+	},
+	"proposedDate": func(p *models.Project, raw json.RawMessage) error {
+		return patchOptionalDate(&p.ProposalDate, raw)
+	},
+	"launchDate": func(p *models.Project, raw json.RawMessage) error {
+		return patchOptionalDate(&p.LaunchDate, raw)
+	},
+	"keyResultIds": func(p *models.Project, raw json.RawMessage) error {
+		return patchStringList(&p.KeyResultIds, raw)
+	},
+	"followers": func(p *models.Project, raw json.RawMessage) error {
+		return patchStringList(&p.Followers, raw)
+	},
+	"productManagers": func(p *models.Project, raw json.RawMessage) error {
+		return patchRole(&p.ProductManagers, raw)
+	},
+	"backendDevelopers": func(p *models.Project, raw json.RawMessage) error {
+		return patchRole(&p.BackendDevelopers, raw)
+	},
+	"frontendDevelopers": func(p *models.Project, raw json.RawMessage) error {
+		return patchRole(&p.FrontendDevelopers, raw)
+	},
+	"qaTesters": func(p *models.Project, raw json.RawMessage) error {
+		return patchRole(&p.QaTesters, raw)
+	},
+	// members 按角色键合并：出现的角色整体替换，值为 null 时移除该角色的成员
+	"members": func(p *models.Project, raw json.RawMessage) error {
+		if isJSONNull(raw) {
+			p.Members = map[string]models.Role{}
+			return nil
+		}
+		var members map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &members); err != nil {
+			return fmt.Errorf("must be an object keyed by role")
+		}
+		for roleKey, roleRaw := range members {
+			role := roleMembers(p, roleKey)
+			if err := patchRole(&role, roleRaw); err != nil {
+				return fmt.Errorf("%s: %v", roleKey, err)
+			}
+			setRoleMembers(p, roleKey, role)
+		}
+		return nil
+	},
+	// customFields 按字段键合并：出现的字段整体替换，值为 null 时清空该字段，类型由 checkCustomFieldValues 校验
+	"customFields": func(p *models.Project, raw json.RawMessage) error {
+		if isJSONNull(raw) {
+			p.CustomFields = map[string]interface{}{}
+			return nil
+		}
+		var values map[string]interface{}
+		if err := json.Unmarshal(raw, &values); err != nil || values == nil {
+			return fmt.Errorf("must be an object keyed by field")
+		}
+		updated := make(map[string]interface{}, len(p.CustomFields)+len(values))
+		for key, value := range p.CustomFields {
+			updated[key] = value
+		}
+		for key, value := range values {
+			if value == nil {
+				delete(updated, key)
+			} else {
+				updated[key] = value
+			}
+		}
+		p.CustomFields = updated
+		return nil
+	},
+	"comments": func(p *models.Project, raw json.RawMessage) error {
+		if isJSONNull(raw) {
+			p.Comments = []models.Comment{}
+			return nil
+		}
+		var comments []models.Comment
+		if err := json.Unmarshal(raw, &comments); err != nil {
+			return fmt.Errorf("must be an array of comments")
+		}
+		if comments == nil {
+			comments = []models.Comment{}
+		}
+		p.Comments = comments
+		return nil
+	},
+	"createdAt": func(p *models.Project, raw json.RawMessage) error {
+		return patchRequiredString(&p.CreatedAt, raw)
+	},
+	"id": func(p *models.Project, raw json.RawMessage) error {
+		var id string
+		if err := json.Unmarshal(raw, &id); err != nil || id != p.ID {
+			return fmt.Errorf("is read-only")
+		}
+		return nil
+	},
+}
+
+// roleFields 内置团队角色字段，修改后需要同步时段表
+var roleFields = map[string]bool{
+	"productManagers":    true,
+	"backendDevelopers":  true,
+	"frontendDevelopers": true,
+	"qaTesters":          true,
+}
+
+// applyProjectPatch 将 merge patch 应用到项目上
+// 返回团队角色是否被修改，以及逐字段的校验错误
+// 未知字段及 version、changeLog 等只读字段被忽略（并发控制使用 If-Match，变更日志由服务端生成）
+func applyProjectPatch(p *models.Project, patch map[string]json.RawMessage) (bool, map[string]string) {
+	fieldErrors := make(map[string]string)
+	teamUpdated := false
+
+	for field, raw := range patch {
+		patcher, ok := projectPatchers[field]
+		if !ok {
+			continue
+		}
+		if err := patcher(p, raw); err != nil {
+			fieldErrors[field] = err.Error()
+			continue
+		}
+		if roleFields[field] || field == "members" {
+			teamUpdated = true
+		}
+	}
+
+	return teamUpdated, fieldErrors
+}
+
+// applyPatch 按字段补丁函数将 merge patch 应用到对象上，返回逐字段的校验错误
+// 未知字段被忽略
+func applyPatch[T any](dst *T, patchers map[string]func(*T, json.RawMessage) error, patch map[string]json.RawMessage) map[string]string {
+	fieldErrors := make(map[string]string)
+	for field, raw := range patch {
+		patcher, ok := patchers[field]
+		if !ok {
+			continue
+		}
+		if err := patcher(dst, raw); err != nil {
+			fieldErrors[field] = err.Error()
+		}
+	}
+	return fieldErrors
+}
+
+// bindMergePatch 读取请求体中的 merge patch，请求体不是 JSON 对象时写入 400 响应并返回 false
+// what 为错误信息中的对象名称，如 "Time slot"
+func bindMergePatch(c *gin.Context, what string) (map[string]json.RawMessage, bool) {
+	var patch map[string]json.RawMessage
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if patch == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": what + " must be a JSON object"})
+		return nil, false
+	}
+	return patch, true
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+func patchRequiredString(dst *string, raw json.RawMessage) error {
+	if isJSONNull(raw) {
+		return fmt.Errorf("is required and cannot be cleared")
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return fmt.Errorf("must be a string")
+	}
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("cannot be empty")
+	}
+	*dst = value
+	return nil
+}
+
+func patchOptionalString(dst **string, raw json.RawMessage) error {
+	if isJSONNull(raw) {
+		*dst = nil
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return fmt.Errorf("must be a string or null")
+	}
+	*dst = &value
+	return nil
+}
+
+// patchOptionalDate 空字符串与 null 均视为清空日期
+func patchOptionalDate(dst **string, raw json.RawMessage) error {
+	if isJSONNull(raw) {
+		*dst = nil
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return fmt.Errorf("must be a date string or null")
+	}
+	if value == "" {
+		*dst = nil
+		return nil
+	}
+	date, ok := normalizeDate(value)
+	if !ok {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+	}
+	*dst = &date
+	return nil
+}
+
+func patchStringList(dst *[]string, raw json.RawMessage) error {
+	if isJSONNull(raw) {
+		*dst = []string{}
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return fmt.Errorf("must be an array of strings")
+	}
+	for _, v := range values {
+		if strings.TrimSpace(v) == "" {
+			return fmt.Errorf("must not contain empty values")
+		}
+	}
+	if values == nil {
+		values = []string{}
+	}
+	*dst = values
+	return nil
+}
+
+func patchRole(dst *models.Role, raw json.RawMessage) error {
+	if isJSONNull(raw) {
+		*dst = models.Role{}
+		return nil
+	}
+	var role models.Role
+	if err := json.Unmarshal(raw, &role); err != nil {
+		return fmt.Errorf("must be an array of team members")
+	}
+	for i, member := range role {
+		if strings.TrimSpace(member.UserID) == "" {
+			return fmt.Errorf("member %d is missing userId", i)
+		}
+	}
+	inheritSlotAllocation(role, *dst)
+	if err := validateRoleTimeSlots(role); err != nil {
+		return err
+	}
+	if role == nil {
+		role = models.Role{}
+	}
+	*dst = role
+	return nil
+}
+
+// normalizeDate 接受 YYYY-MM-DD 或 RFC3339 格式，统一返回 YYYY-MM-DD
+func normalizeDate(s string) (string, bool) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t.Format("2006-01-02"), true
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.Format("2006-01-02"), true
+	}
+	return "", false
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"project-management-backend/internal/models"
+)
+
+func TestApplyProjectPatch(t *testing.T) {
+	s := func(v string) *string { return &v }
+	base := func() models.Project {
+		return models.Project{
+			ID:              "p1",
+			Name:            "旧名称",
+			Priority:        "P1",
+			Status:          "开发中",
+			BusinessProblem: s("旧问题"),
+			LaunchDate:      s("2026-10-01"),
+			KeyResultIds:    []string{"kr1"},
+			ProductManagers: models.Role{{UserID: "u1"}},
+		}
+	}
+	tests := []struct {
+		name     string
+		patch    string
+		wantErr  string
+		wantTeam bool
+		check    func(t *testing.T, p models.Project)
+	}{
+		{name: "set required string", patch: `{"name":"新名称"}`, check: func(t *testing.T, p models.Project) {
+			if p.Name != "新名称" || p.Priority != "P1" {
+				t.Errorf("name = %q, priority = %q", p.Name, p.Priority)
+			}
+		}},
+		{name: "null clears optional string", patch: `{"businessProblem":null}`, check: func(t *testing.T, p models.Project) {
+			if p.BusinessProblem != nil {
+				t.Errorf("businessProblem = %q, want nil", *p.BusinessProblem)
+			}
+		}},
+		{name: "empty string clears date", patch: `{"launchDate":""}`, check: func(t *testing.T, p models.Project) {
+			if p.LaunchDate != nil {
+				t.Errorf("launchDate = %q, want nil", *p.LaunchDate)
+			}
+		}},
+		{name: "RFC3339 date normalized", patch: `{"proposedDate":"2026-09-01T08:00:00Z"}`, check: func(t *testing.T, p models.Project) {
+			if p.ProposalDate == nil || *p.ProposalDate != "2026-09-01" {
+				t.Errorf("proposalDate = %v", p.ProposalDate)
+			}
+		}},
+		{name: "null clears list", patch: `{"keyResultIds":null}`, check: func(t *testing.T, p models.Project) {
+			if !reflect.DeepEqual(p.KeyResultIds, []string{}) {
+				t.Errorf("keyResultIds = %#v, want empty", p.KeyResultIds)
+			}
+		}},
+		{name: "role update marks team", patch: `{"qaTesters":[{"userId":"u2","timeSlots":[]}]}`, wantTeam: true, check: func(t *testing.T, p models.Project) {
+			if len(p.QaTesters) != 1 || p.QaTesters[0].UserID != "u2" {
+				t.Errorf("qaTesters = %+v", p.QaTesters)
+			}
+		}},
+		{name: "unknown and read-only fields ignored", patch: `{"budget":1,"version":9}`, check: func(t *testing.T, p models.Project) {
+			if !reflect.DeepEqual(p, base()) {
+				t.Errorf("project changed: %+v", p)
+			}
+		}},
+		{name: "same id accepted", patch: `{"id":"p1"}`},
+		{name: "null required field", patch: `{"name":null}`, wantErr: "name"},
+		{name: "blank required field", patch: `{"status":"  "}`, wantErr: "status"},
+		{name: "wrong type", patch: `{"weeklyUpdate":3}`, wantErr: "weeklyUpdate"},
+		{name: "invalid date", patch: `{"launchDate":"2026/10/01"}`, wantErr: "launchDate"},
+		{name: "empty list value", patch: `{"followers":["u1",""]}`, wantErr: "followers"},
+		{name: "member without userId", patch: `{"productManagers":[{"userId":""}]}`, wantErr: "productManagers"},
+		{name: "id change", patch: `{"id":"p2"}`, wantErr: "id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatal(err)
+			}
+			p := base()
+			teamUpdated, fieldErrors := applyProjectPatch(&p, patch)
+			if tt.wantErr != "" {
+				if _, ok := fieldErrors[tt.wantErr]; !ok {
+					t.Fatalf("expected error on %s, got %v", tt.wantErr, fieldErrors)
+				}
+				return
+			}
+			if len(fieldErrors) > 0 {
+				t.Fatalf("unexpected errors: %v", fieldErrors)
+			}
+			if teamUpdated != tt.wantTeam {
+				t.Errorf("teamUpdated = %v, want %v", teamUpdated, tt.wantTeam)
+			}
+			if tt.check != nil {
+				tt.check(t, p)
+			}
+		})
+	}
+}
+
+func TestNormalizeDate(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"2026-10-17", "2026-10-17", true},
+		{"2026-10-17T23:30:00+08:00", "2026-10-17", true},
+		{"2026-02-30", "", false},
+		{"17/10/2026", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := normalizeDate(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("normalizeDate(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,417 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// projectColumns 项目查询的公共字段列表，顺序需与 scanProject 保持一致
+const projectColumns = `
+	id, name, priority, business_problem, key_result_ids, weekly_update,
+	last_week_update, status, product_managers, backend_developers,
+	frontend_developers, qa_testers, proposal_date, launch_date,
+	created_at, followers, comments, change_log, version,
+	deleted_at, deleted_by, archived_at, archived_by, status_changed_at, members, custom_fields,
+	health, health_reason, last_week_health`
+
+// maxProjectPageSize 单页最多返回的项目数
+const maxProjectPageSize = 200
+
+// projectSortColumns 允许排序的字段及其对应的SQL排序表达式
+// 统一转换为文本，便于游标比较（日期使用ISO格式，字典序与时间序一致）
+var projectSortColumns = map[string]string{
+	"name":         "p.name",
+	"priority":     "p.priority",
+	"status":       "p.status",
+	"proposedDate": "COALESCE(to_char(p.proposal_date, 'YYYY-MM-DD'), '')",
+	"launchDate":   "COALESCE(to_char(p.launch_date, 'YYYY-MM-DD'), '')",
+	"createdAt":    `COALESCE(to_char(p.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US'), '')`,
+}
+
+// rowScanner 兼容 *sql.Row 与 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// queryer 兼容 *sql.DB 与 *sql.Tx
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// checkProjectExists 校验项目存在且未删除，失败时已写入响应
+func (h *Handler) checkProjectExists(c *gin.Context, q queryer, projectID string) bool {
+	var exists bool
+	err := q.QueryRow("SELECT EXISTS (SELECT 1 FROM projects WHERE id = $1 AND deleted_at IS NULL)", projectID).Scan(&exists)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return false
+	}
+	return true
+}
+
+// scanProject 扫描一行项目数据，extra 用于接收 projectColumns 之后的附加列
+func scanProject(row rowScanner, extra ...interface{}) (models.Project, error) {
+	var p models.Project
+	var keyResultIds pq.StringArray
+	var followers pq.StringArray
+	var productManagers, backendDevelopers, frontendDevelopers, qaTesters []byte
+	var comments, changeLog, members, customFields []byte
+
+	dest := []interface{}{
+		&p.ID, &p.Name, &p.Priority, &p.BusinessProblem, &keyResultIds,
+		&p.WeeklyUpdate, &p.LastWeekUpdate, &p.Status, &productManagers,
+		&backendDevelopers, &frontendDevelopers, &qaTesters,
+		&p.ProposalDate, &p.LaunchDate, &p.CreatedAt, &followers, &comments, &changeLog,
+		&p.Version, &p.DeletedAt, &p.DeletedBy,
+		&p.ArchivedAt, &p.ArchivedBy, &p.StatusChangedAt, &members, &customFields,
+		&p.Health, &p.HealthReason, &p.LastWeekHealth,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return p, err
+	}
+
+	// 转换数组类型
+	p.KeyResultIds = []string(keyResultIds)
+	p.Followers = []string(followers)
+
+	// 解析JSONB字段
+	json.Unmarshal(productManagers, &p.ProductManagers)
+	json.Unmarshal(backendDevelopers, &p.BackendDevelopers)
+	json.Unmarshal(frontendDevelopers, &p.FrontendDevelopers)
+	json.Unmarshal(qaTesters, &p.QaTesters)
+	json.Unmarshal(comments, &p.Comments)
+	json.Unmarshal(changeLog, &p.ChangeLog)
+	json.Unmarshal(members, &p.Members)
+	if p.Members == nil {
+		p.Members = make(map[string]models.Role)
+	}
+	json.Unmarshal(customFields, &p.CustomFields)
+	if p.CustomFields == nil {
+		p.CustomFields = make(map[string]interface{})
+	}
+
+	return p, nil
+}
+
+// projectListQuery 项目列表的筛选、排序与分页参数
+type projectListQuery struct {
+	Statuses     []string
+	Priorities   []string
+	MemberID     string
+	KeyResultID  string
+	LabelIDs     []string
+	LinkKinds    []string
+	DeptID       *int
+	LaunchFrom   string
+	LaunchTo     string
+	ProposedFrom string
+	ProposedTo   string
+	Search       string
+
+	IncludeArchived bool
+
+	SortBy   string
+	SortDesc bool
+	Limit    int
+	Cursor   *projectCursor
+}
+
+// projectCursor 游标分页的位置信息，记录上一页最后一条记录的排序值和ID
+type projectCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// sortKey 返回排序字段与方向的组合标识，用于校验游标是否与当前排序匹配
+func (q *projectListQuery) sortKey() string {
+	if q.SortDesc {
+		return q.SortBy + ":desc"
+	}
+	return q.SortBy + ":asc"
+}
+
+func encodeProjectCursor(cursor projectCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeProjectCursor(raw string) (*projectCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	var cursor projectCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// queryList 读取可重复或逗号分隔的查询参数，如 status=a,b&status=c
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(key) {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// isValidDate 校验 YYYY-MM-DD 格式的日期
+func isValidDate(s string) bool {
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
+
+// parseProjectListQuery 解析并校验项目列表的查询参数
+func parseProjectListQuery(c *gin.Context) (*projectListQuery, error) {
+	q := &projectListQuery{
+		Statuses:        queryList(c, "status"),
+		Priorities:      queryList(c, "priority"),
+		MemberID:        strings.TrimSpace(c.Query("memberId")),
+		KeyResultID:     strings.TrimSpace(c.Query("krId")),
+		LabelIDs:        queryList(c, "labelId"),
+		LinkKinds:       queryList(c, "linkKind"),
+		LaunchFrom:      c.Query("launchFrom"),
+		LaunchTo:        c.Query("launchTo"),
+		ProposedFrom:    c.Query("proposedFrom"),
+		ProposedTo:      c.Query("proposedTo"),
+		Search:          strings.TrimSpace(c.Query("q")),
+		IncludeArchived: c.Query("includeArchived") == "true",
+		SortBy:          c.DefaultQuery("sortBy", "createdAt"),
+		SortDesc:        true,
+	}
+
+	if raw := c.Query("deptId"); raw != "" {
+		deptID, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deptId: %s", raw)
+		}
+		q.DeptID = &deptID
+	}
+
+	for name, value := range map[string]string{
+		"launchFrom":   q.LaunchFrom,
+		"launchTo":     q.LaunchTo,
+		"proposedFrom": q.ProposedFrom,
+		"proposedTo":   q.ProposedTo,
+	} {
+		if value != "" && !isValidDate(value) {
+			return nil, fmt.Errorf("invalid %s: %s, expected YYYY-MM-DD", name, value)
+		}
+	}
+
+	if _, ok := projectSortColumns[q.SortBy]; !ok {
+		return nil, fmt.Errorf("invalid sortBy: %s", q.SortBy)
+	}
+	switch strings.ToLower(c.DefaultQuery("sortOrder", "desc")) {
+	case "asc":
+		q.SortDesc = false
+	case "desc":
+		q.SortDesc = true
+	default:
+		return nil, fmt.Errorf("invalid sortOrder: %s", c.Query("sortOrder"))
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid limit: %s", raw)
+		}
+		if limit > maxProjectPageSize {
+			limit = maxProjectPageSize
+		}
+		q.Limit = limit
+	}
+
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := decodeProjectCursor(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		if cursor.Sort != q.sortKey() {
+			return nil, fmt.Errorf("cursor does not match current sort order")
+		}
+		q.Cursor = cursor
+	}
+
+	return q, nil
+}
+
+// sqlBuilder 拼接带位置参数的SQL条件
+type sqlBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg 追加一个参数并返回其占位符
+func (b *sqlBuilder) arg(v interface{}) string {
+	b.args = append(b.args, v)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+func (b *sqlBuilder) where(cond string) {
+	b.conditions = append(b.conditions, cond)
+}
+
+func (b *sqlBuilder) whereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// escapeLike 转义 LIKE 模式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// memberCondition 判断用户是否出现在任一角色中（含自定义角色）
+func memberCondition(userParam string) string {
+	member := "jsonb_build_array(jsonb_build_object('userId', " + userParam + "::text))"
+	return "(p.product_managers @> " + member +
+		" OR p.backend_developers @> " + member +
+		" OR p.frontend_developers @> " + member +
+		" OR p.qa_testers @> " + member +
+		" OR EXISTS (SELECT 1 FROM jsonb_each(p.members) m WHERE m.value @> " + member + "))"
+}
+
+// applyFilters 将筛选条件写入 builder（不包含游标条件）
+// 已删除的项目始终排除，已归档的项目默认排除
+func (q *projectListQuery) applyFilters(b *sqlBuilder) {
+	b.where("p.deleted_at IS NULL")
+	if !q.IncludeArchived {
+		b.where("p.archived_at IS NULL")
+	}
+	if len(q.Statuses) > 0 {
+		b.where("p.status = ANY(" + b.arg(pq.Array(q.Statuses)) + ")")
+	}
+	if len(q.Priorities) > 0 {
+		b.where("p.priority = ANY(" + b.arg(pq.Array(q.Priorities)) + ")")
+	}
+	if q.MemberID != "" {
+		b.where(memberCondition(b.arg(q.MemberID)))
+	}
+	if q.KeyResultID != "" {
+		b.where(b.arg(q.KeyResultID) + " = ANY(p.key_result_ids)")
+	}
+	if len(q.LabelIDs) > 0 {
+		b.where("EXISTS (SELECT 1 FROM project_labels pl WHERE pl.project_id = p.id AND pl.label_id = ANY(" +
+			b.arg(pq.Array(q.LabelIDs)) + "))")
+	}
+	if len(q.LinkKinds) > 0 {
+		b.where("EXISTS (SELECT 1 FROM project_links pk WHERE pk.project_id = p.id AND pk.kind = ANY(" +
+			b.arg(pq.Array(q.LinkKinds)) + "))")
+	}
+	if q.DeptID != nil {
+		b.where("EXISTS (SELECT 1 FROM users u WHERE u.dept_id = " + b.arg(*q.DeptID) +
+			" AND " + memberCondition("u.id") + ")")
+	}
+	if q.LaunchFrom != "" {
+		b.where("p.launch_date >= " + b.arg(q.LaunchFrom))
+	}
+	if q.LaunchTo != "" {
+		b.where("p.launch_date <= " + b.arg(q.LaunchTo))
+	}
+	if q.ProposedFrom != "" {
+		b.where("p.proposal_date >= " + b.arg(q.ProposedFrom))
+	}
+	if q.ProposedTo != "" {
+		b.where("p.proposal_date <= " + b.arg(q.ProposedTo))
+	}
+	if q.Search != "" {
+		pattern := b.arg("%" + escapeLike(q.Search) + "%")
+		b.where("(p.name ILIKE " + pattern +
+			" OR p.business_problem ILIKE " + pattern +
+			" OR p.weekly_update ILIKE " + pattern + ")")
+	}
+}
+
+// countProjects 统计满足筛选条件的项目总数
+func (h *Handler) countProjects(q *projectListQuery) (int, error) {
+	b := &sqlBuilder{}
+	q.applyFilters(b)
+
+	var total int
+	err := h.db.QueryRow("SELECT COUNT(*) FROM projects p"+b.whereClause(), b.args...).Scan(&total)
+	return total, err
+}
+
+// queryProjects 按筛选、排序与游标查询一页项目，返回项目列表与下一页游标
+func (h *Handler) queryProjects(q *projectListQuery) ([]models.Project, string, error) {
+	b := &sqlBuilder{}
+	q.applyFilters(b)
+
+	sortExpr := projectSortColumns[q.SortBy]
+	direction, comparator := "ASC", ">"
+	if q.SortDesc {
+		direction, comparator = "DESC", "<"
+	}
+
+	if q.Cursor != nil {
+		b.where("(" + sortExpr + ", p.id) " + comparator +
+			" (" + b.arg(q.Cursor.Value) + ", " + b.arg(q.Cursor.ID) + ")")
+	}
+
+	query := "SELECT " + projectColumns + ", " + sortExpr + " AS sort_key FROM projects p" +
+		b.whereClause() +
+		" ORDER BY " + sortExpr + " " + direction + ", p.id " + direction
+	if q.Limit > 0 {
+		// 多取一条用于判断是否还有下一页
+		query += " LIMIT " + b.arg(q.Limit+1)
+	}
+
+	rows, err := h.db.Query(query, b.args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var projects []models.Project
+	var sortValues []string
+	for rows.Next() {
+		var sortValue sql.NullString
+		p, err := scanProject(rows, &sortValue)
+		if err != nil {
+			return nil, "", err
+		}
+		projects = append(projects, p)
+		sortValues = append(sortValues, sortValue.String)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if q.Limit > 0 && len(projects) > q.Limit {
+		projects = projects[:q.Limit]
+		last := projects[q.Limit-1]
+		nextCursor = encodeProjectCursor(projectCursor{
+			Sort:  q.sortKey(),
+			Value: sortValues[q.Limit-1],
+			ID:    last.ID,
+		})
+	}
+
+	return projects, nextCursor, nil
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"database/sql"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"project-management-backend/internal/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// openTestDB 连接 TEST_DATABASE_URL 指定的测试库，未设置时跳过测试
+// 接口使用 PostgreSQL 专有语法（JSONB 操作符、ILIKE、to_char 等），数据库测试只支持 PostgreSQL
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if !strings.HasPrefix(url, "postgresql://") {
+		t.Skip("TEST_DATABASE_URL is not a postgresql:// URL, skipping database test")
+	}
+	db, err := database.Initialize(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// insertTestProject 插入一个测试项目，测试结束时删除
+func insertTestProject(t *testing.T, db *sql.DB, id, name, priority, status string) {
+	t.Helper()
+	_, err := db.Exec(`INSERT INTO projects (id, name, priority, status) VALUES ($1, $2, $3, $4)`,
+		id, name, priority, status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Exec("DELETE FROM projects WHERE id = $1", id) })
+}
+
+func newQueryContext(rawQuery string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/api/projects?"+rawQuery, nil)
+	return c
+}
+
+func TestProjectCursorRoundTrip(t *testing.T) {
+	cursor := projectCursor{Sort: "name:asc", Value: "项目 A", ID: "p1"}
+	decoded, err := decodeProjectCursor(encodeProjectCursor(cursor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *decoded != cursor {
+		t.Errorf("decoded cursor = %+v, want %+v", *decoded, cursor)
+	}
+
+	for _, raw := range []string{"not base64!", "bm90IGpzb24"} {
+		if _, err := decodeProjectCursor(raw); err == nil {
+			t.Errorf("decodeProjectCursor(%q) succeeded, want error", raw)
+		}
+	}
+}
+
+func TestParseProjectListQuery(t *testing.T) {
+	nameCursor := encodeProjectCursor(projectCursor{Sort: "name:asc", Value: "a", ID: "p1"})
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+		check   func(t *testing.T, q *projectListQuery)
+	}{
+		{name: "defaults", query: "", check: func(t *testing.T, q *projectListQuery) {
+			if q.SortBy != "createdAt" || !q.SortDesc || q.Limit != 0 || q.Cursor != nil {
+				t.Errorf("unexpected defaults: %+v", q)
+			}
+		}},
+		{name: "repeated and comma separated lists", query: "status=a,b&status=c&priority=P0,+", check: func(t *testing.T, q *projectListQuery) {
+			if !reflect.DeepEqual(q.Statuses, []string{"a", "b", "c"}) || !reflect.DeepEqual(q.Priorities, []string{"P0"}) {
+				t.Errorf("statuses = %v, priorities = %v", q.Statuses, q.Priorities)
+			}
+		}},
+		{name: "limit capped", query: "limit=1000", check: func(t *testing.T, q *projectListQuery) {
+			if q.Limit != maxProjectPageSize {
+				t.Errorf("limit = %d, want %d", q.Limit, maxProjectPageSize)
+			}
+		}},
+		{name: "matching cursor", query: "sortBy=name&sortOrder=asc&cursor=" + nameCursor, check: func(t *testing.T, q *projectListQuery) {
+			if q.Cursor == nil || q.Cursor.ID != "p1" {
+				t.Errorf("cursor = %+v", q.Cursor)
+			}
+		}},
+		{name: "cursor from another sort order", query: "sortBy=name&sortOrder=desc&cursor=" + nameCursor, wantErr: true},
+		{name: "invalid cursor", query: "cursor=%21%21", wantErr: true},
+		{name: "unknown sort field", query: "sortBy=budget", wantErr: true},
+		{name: "invalid sort order", query: "sortOrder=up", wantErr: true},
+		{name: "zero limit", query: "limit=0", wantErr: true},
+		{name: "invalid deptId", query: "deptId=abc", wantErr: true},
+		{name: "invalid date", query: "launchFrom=2026-13-01", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := parseProjectListQuery(newQueryContext(tt.query))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", q)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(t, q)
+		})
+	}
+}
+
+func TestApplyFilters(t *testing.T) {
+	deptID := 7
+	q := &projectListQuery{
+		Statuses:   []string{"开发中", "测试中"},
+		MemberID:   "u1",
+		DeptID:     &deptID,
+		LaunchFrom: "2026-10-01",
+		Search:     "50%_off",
+	}
+	b := &sqlBuilder{}
+	q.applyFilters(b)
+
+	if len(b.args) != 5 {
+		t.Fatalf("got %d args, want 5: %v", len(b.args), b.args)
+	}
+	where := b.whereClause()
+	for _, want := range []string{
+		"p.deleted_at IS NULL",
+		"p.status = ANY($1)",
+		"p.product_managers @> jsonb_build_array(jsonb_build_object('userId', $2::text))",
+		"u.dept_id = $3",
+		"p.launch_date >= $4",
+		"p.name ILIKE $5",
+	} {
+		if !strings.Contains(where, want) {
+			t.Errorf("where clause missing %q: %s", want, where)
+		}
+	}
+	if !reflect.DeepEqual(b.args[0], pq.Array(q.Statuses)) {
+		t.Errorf("status arg = %#v", b.args[0])
+	}
+	if b.args[2] != 7 {
+		t.Errorf("dept arg = %v", b.args[2])
+	}
+	if b.args[4] != `%50\%\_off%` {
+		t.Errorf("search pattern = %v, want wildcards escaped", b.args[4])
+	}
+
+	// 未设置筛选条件时只保留默认条件，不产生参数
+	empty := &sqlBuilder{}
+	(&projectListQuery{}).applyFilters(empty)
+	if len(empty.args) != 0 {
+		t.Errorf("empty query produced args %v", empty.args)
+	}
+}
+
+func TestQueryProjectsPagination(t *testing.T) {
+	db := openTestDB(t)
+	h := &Handler{db: db}
+
+	// 名称中带唯一标记，通过全文筛选与库中其他数据隔离
+	token := "pagination-" + strings.ReplaceAll(t.Name(), "/", "-")
+	for i, name := range []string{"c", "a", "b"} {
+		insertTestProject(t, db, token+"-"+string(rune('0'+i)), token+" "+name, "P1", "开发中")
+	}
+
+	q := &projectListQuery{Search: token, SortBy: "name", Limit: 2}
+	total, err := h.countProjects(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 {
+		t.Errorf("total = %d, want 3", total)
+	}
+
+	var names []string
+	pages := 0
+	for {
+		projects, next, err := h.queryProjects(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages++
+		for _, p := range projects {
+			names = append(names, strings.TrimPrefix(p.Name, token+" "))
+		}
+		if next == "" {
+			break
+		}
+		cursor, err := decodeProjectCursor(next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q.Cursor = cursor
+	}
+	if pages != 2 || !reflect.DeepEqual(names, []string{"a", "b", "c"}) {
+		t.Errorf("got %v over %d pages, want [a b c] over 2 pages", names, pages)
+	}
+
+	q = &projectListQuery{Search: token, Priorities: []string{"P0"}, SortBy: "name"}
+	projects, _, err := h.queryProjects(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 0 {
+		t.Errorf("priority filter returned %d projects, want 0", len(projects))
+	}
+}
+
+func TestApplyFiltersArchived(t *testing.T) {
+	for _, includeArchived := range []bool{false, true} {
+		b := &sqlBuilder{}
+		(&projectListQuery{IncludeArchived: includeArchived}).applyFilters(b)
+		excluded := strings.Contains(b.whereClause(), "p.archived_at IS NULL")
+		if excluded == includeArchived {
+			t.Errorf("includeArchived=%v: archived projects excluded = %v", includeArchived, excluded)
+		}
+	}
+}
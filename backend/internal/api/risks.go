@@ -0,0 +1,322 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// riskClosedStatus 风险关闭状态，其余状态均视为未关闭
+const riskClosedStatus = "已关闭"
+
+// highRiskSeverity 高风险的严重度下限（高×中及以上）
+const highRiskSeverity = 6
+
+// riskColumns 构成风险的 project_risks 列（别名 r），与 scanRisk 的扫描顺序一致
+const riskColumns = "r.id, r.project_id, r.description, r.probability, r.impact, r.owner_id, r.mitigation, r.status, r.due_date, r.created_at, r.updated_at"
+
+// riskLevelScore 返回风险等级的分值（低=1、中=2、高=3），未知等级为 0
+func riskLevelScore(level string) int {
+	for i, l := range models.RiskLevels {
+		if l == level {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// scanRisk 扫描一行风险数据并计算严重度，extra 用于接收 riskColumns 之后的附加列
+func scanRisk(row rowScanner, extra ...interface{}) (models.Risk, error) {
+	var r models.Risk
+	var dueDate *string
+	dest := append([]interface{}{
+		&r.ID, &r.ProjectID, &r.Description, &r.Probability, &r.Impact, &r.OwnerID, &r.Mitigation,
+		&r.Status, &dueDate, &r.CreatedAt, &r.UpdatedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return r, err
+	}
+	if dueDate != nil {
+		date := derefDate(dueDate)
+		r.DueDate = &date
+	}
+	r.Severity = riskLevelScore(r.Probability) * riskLevelScore(r.Impact)
+	return r, nil
+}
+
+// patchOneOf 必填的枚举字段，值必须在 allowed 中
+func patchOneOf(dst *string, raw json.RawMessage, allowed []string) error {
+	if err := patchRequiredString(dst, raw); err != nil {
+		return err
+	}
+	for _, value := range allowed {
+		if *dst == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+}
+
+// riskPatchers 风险支持的 merge patch 字段
+var riskPatchers = map[string]func(r *models.Risk, raw json.RawMessage) error{
+	"description": func(r *models.Risk, raw json.RawMessage) error {
+		if err := patchRequiredString(&r.Description, raw); err != nil {
+			return err
+		}
+		r.Description = strings.TrimSpace(r.Description)
+		return nil
+	},
+	"probability": func(r *models.Risk, raw json.RawMessage) error {
+		return patchOneOf(&r.Probability, raw, models.RiskLevels)
+	},
+	"impact": func(r *models.Risk, raw json.RawMessage) error {
+		return patchOneOf(&r.Impact, raw, models.RiskLevels)
+	},
+	"ownerId": func(r *models.Risk, raw json.RawMessage) error {
+		return patchOptionalID(&r.OwnerID, raw)
+	},
+	"mitigation": func(r *models.Risk, raw json.RawMessage) error {
+		return patchOptionalString(&r.Mitigation, raw)
+	},
+	"status": func(r *models.Risk, raw json.RawMessage) error {
+		return patchOneOf(&r.Status, raw, models.RiskStatuses)
+	},
+	"dueDate": func(r *models.Risk, raw json.RawMessage) error {
+		return patchOptionalDate(&r.DueDate, raw)
+	},
+}
+
+// sortRisks 按严重度从高到低排序，同严重度时截止日期早的在前，无截止日期的排最后
+func sortRisks(risks []models.Risk) {
+	sort.SliceStable(risks, func(i, j int) bool {
+		a, b := risks[i], risks[j]
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if (a.DueDate == nil) != (b.DueDate == nil) {
+			return a.DueDate != nil
+		}
+		if a.DueDate != nil && *a.DueDate != *b.DueDate {
+			return *a.DueDate < *b.DueDate
+		}
+		return a.CreatedAt < b.CreatedAt
+	})
+}
+
+// loadRisk 读取项目的单个风险
+func (h *Handler) loadRisk(projectID, riskID string) (models.Risk, error) {
+	return scanRisk(h.db.QueryRow(
+		"SELECT "+riskColumns+" FROM project_risks r WHERE r.id = $1 AND r.project_id = $2", riskID, projectID))
+}
+
+// queryRisks 执行风险查询并按严重度排序，minSeverity 为 0 时不过滤
+func (h *Handler) queryRisks(query string, minSeverity int, args ...interface{}) ([]models.Risk, error) {
+	rows, err := h.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	risks := []models.Risk{}
+	for rows.Next() {
+		var projectName string
+		r, err := scanRisk(rows, &projectName)
+		if err != nil {
+			return nil, err
+		}
+		if r.Severity < minSeverity {
+			continue
+		}
+		r.ProjectName = projectName
+		risks = append(risks, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sortRisks(risks)
+	return risks, nil
+}
+
+// GetProjectRisks 获取项目的风险登记，按严重度排序
+// 支持按 status、ownerId 筛选，open=true 时只返回未关闭的风险
+func (h *Handler) GetProjectRisks(c *gin.Context) {
+	projectID := c.Param("projectId")
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	// 项目内查询不返回项目名称
+	query := "SELECT " + riskColumns + ", '' FROM project_risks r WHERE r.project_id = $1"
+	args := []interface{}{projectID}
+	if status := c.Query("status"); status != "" {
+		args = append(args, status)
+		query += " AND r.status = $" + strconv.Itoa(len(args))
+	}
+	if ownerID := c.Query("ownerId"); ownerID != "" {
+		args = append(args, ownerID)
+		query += " AND r.owner_id = $" + strconv.Itoa(len(args))
+	}
+	if c.Query("open") == "true" {
+		args = append(args, riskClosedStatus)
+		query += " AND r.status <> $" + strconv.Itoa(len(args))
+	}
+
+	risks, err := h.queryRisks(query, 0, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, risks)
+}
+
+// CreateProjectRisk 新增风险，description、probability、impact 必填，status 默认为待处理
+func (h *Handler) CreateProjectRisk(c *gin.Context) {
+	projectID := c.Param("projectId")
+	patch, ok := bindMergePatch(c, "Risk")
+	if !ok {
+		return
+	}
+
+	risk := models.Risk{ID: newID("risk"), ProjectID: projectID, Status: models.RiskStatuses[0]}
+	fieldErrors := applyPatch(&risk, riskPatchers, patch)
+	for _, field := range []string{"description", "probability", "impact"} {
+		if _, ok := patch[field]; !ok {
+			fieldErrors[field] = "is required"
+		}
+	}
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid risk", "fields": fieldErrors})
+		return
+	}
+
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	_, err := h.db.Exec(`
+		INSERT INTO project_risks (id, project_id, description, probability, impact, owner_id, mitigation, status, due_date)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		risk.ID, projectID, risk.Description, risk.Probability, risk.Impact, risk.OwnerID, risk.Mitigation,
+		risk.Status, risk.DueDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save risk: " + err.Error()})
+		return
+	}
+
+	saved, err := h.loadRisk(projectID, risk.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, saved)
+}
+
+// UpdateProjectRisk 更新风险（JSON Merge Patch）
+func (h *Handler) UpdateProjectRisk(c *gin.Context) {
+	projectID := c.Param("projectId")
+	riskID := c.Param("riskId")
+	patch, ok := bindMergePatch(c, "Risk")
+	if !ok {
+		return
+	}
+
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	risk, err := h.loadRisk(projectID, riskID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Risk not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if fieldErrors := applyPatch(&risk, riskPatchers, patch); len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid risk", "fields": fieldErrors})
+		return
+	}
+
+	_, err = h.db.Exec(`
+		UPDATE project_risks
+		SET description = $1, probability = $2, impact = $3, owner_id = $4, mitigation = $5, status = $6, due_date = $7,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8 AND project_id = $9`,
+		risk.Description, risk.Probability, risk.Impact, risk.OwnerID, risk.Mitigation, risk.Status, risk.DueDate,
+		riskID, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save risk: " + err.Error()})
+		return
+	}
+
+	saved, err := h.loadRisk(projectID, riskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, saved)
+}
+
+// DeleteProjectRisk 删除风险
+func (h *Handler) DeleteProjectRisk(c *gin.Context) {
+	projectID := c.Param("projectId")
+	riskID := c.Param("riskId")
+
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	result, err := h.db.Exec("DELETE FROM project_risks WHERE id = $1 AND project_id = $2", riskID, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Risk not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// GetRiskReport 跨项目的未关闭风险，默认只返回高风险（严重度 >= 6），用于周会过会
+// 不包含已删除和已归档的项目，支持 minSeverity（1-9）与 ownerId 筛选
+func (h *Handler) GetRiskReport(c *gin.Context) {
+	minSeverity := highRiskSeverity
+	if raw := c.Query("minSeverity"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > 9 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "minSeverity must be an integer between 1 and 9"})
+			return
+		}
+		minSeverity = n
+	}
+
+	query := `
+		SELECT ` + riskColumns + `, p.name
+		FROM project_risks r
+		JOIN projects p ON p.id = r.project_id
+		WHERE p.deleted_at IS NULL AND p.archived_at IS NULL AND r.status <> $1`
+	args := []interface{}{riskClosedStatus}
+	if ownerID := c.Query("ownerId"); ownerID != "" {
+		args = append(args, ownerID)
+		query += " AND r.owner_id = $" + strconv.Itoa(len(args))
+	}
+
+	risks, err := h.queryRisks(query, minSeverity, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, risks)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"project-management-backend/internal/models"
+)
+
+func TestRiskLevelScore(t *testing.T) {
+	tests := map[string]int{"低": 1, "中": 2, "高": 3, "": 0, "极高": 0}
+	for level, want := range tests {
+		if got := riskLevelScore(level); got != want {
+			t.Errorf("riskLevelScore(%q) = %d, want %d", level, got, want)
+		}
+	}
+}
+
+func TestSortRisks(t *testing.T) {
+	s := func(v string) *string { return &v }
+	risks := []models.Risk{
+		{ID: "low", Severity: 1, CreatedAt: "2026-01-01"},
+		{ID: "high-no-due", Severity: 9, CreatedAt: "2026-01-01"},
+		{ID: "high-late-due", Severity: 9, DueDate: s("2026-03-01"), CreatedAt: "2026-01-01"},
+		{ID: "high-early-due-new", Severity: 9, DueDate: s("2026-02-01"), CreatedAt: "2026-01-02"},
+		{ID: "high-early-due-old", Severity: 9, DueDate: s("2026-02-01"), CreatedAt: "2026-01-01"},
+		{ID: "medium", Severity: 4, CreatedAt: "2026-01-01"},
+	}
+	sortRisks(risks)
+
+	var got []string
+	for _, r := range risks {
+		got = append(got, r.ID)
+	}
+	want := []string{"high-early-due-old", "high-early-due-new", "high-late-due", "high-no-due", "medium", "low"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sortRisks order = %v, want %v", got, want)
+	}
+}
+
+func TestApplyRiskPatch(t *testing.T) {
+	risk := models.Risk{Status: models.RiskStatuses[0]}
+	fieldErrors := applyPatch(&risk, riskPatchers, map[string]json.RawMessage{
+		"description": json.RawMessage(`"  供应商延期  "`),
+		"probability": json.RawMessage(`"高"`),
+		"impact":      json.RawMessage(`"中"`),
+		"dueDate":     json.RawMessage(`"2026-11-01"`),
+	})
+	if len(fieldErrors) > 0 {
+		t.Fatalf("unexpected errors: %v", fieldErrors)
+	}
+	if risk.Description != "供应商延期" || risk.Probability != "高" || risk.Impact != "中" {
+		t.Errorf("risk = %+v", risk)
+	}
+
+	fieldErrors = applyPatch(&risk, riskPatchers, map[string]json.RawMessage{
+		"description": json.RawMessage(`"  "`),
+		"probability": json.RawMessage(`"极高"`),
+		"status":      json.RawMessage(`"done"`),
+		"severity":    json.RawMessage(`9`),
+	})
+	for _, field := range []string{"description", "probability", "status"} {
+		if fieldErrors[field] == "" {
+			t.Errorf("expected %s error, got %v", field, fieldErrors)
+		}
+	}
+	// severity 由可能性与影响计算，补丁中的值被忽略
+	if _, ok := fieldErrors["severity"]; ok {
+		t.Errorf("unexpected severity error: %v", fieldErrors)
+	}
+}
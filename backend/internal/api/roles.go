@@ -0,0 +1,313 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// builtInRoleKeys 内置角色键，按展示顺序排列
+var builtInRoleKeys = []string{"productManagers", "backendDevelopers", "frontendDevelopers", "qaTesters"}
+
+// roleKeyPattern 自定义角色键格式，与内置角色保持一致的驼峰风格
+var roleKeyPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,49}$`)
+
+// builtInRole 返回内置角色对应的项目字段，非内置角色返回 nil
+func builtInRole(p *models.Project, roleKey string) *models.Role {
+	switch roleKey {
+	case "productManagers":
+		return &p.ProductManagers
+	case "backendDevelopers":
+		return &p.BackendDevelopers
+	case "frontendDevelopers":
+		return &p.FrontendDevelopers
+	case "qaTesters":
+		return &p.QaTesters
+	}
+	return nil
+}
+
+// roleMembers 返回项目中某角色的成员
+func roleMembers(p *models.Project, roleKey string) models.Role {
+	if role := builtInRole(p, roleKey); role != nil {
+		return *role
+	}
+	return p.Members[roleKey]
+}
+
+// setRoleMembers 设置项目中某角色的成员，自定义角色没有成员时移除该角色
+// Members 会被复制后再修改，避免影响共享同一 map 的项目副本（如变更前的快照）
+func setRoleMembers(p *models.Project, roleKey string, members models.Role) {
+	if role := builtInRole(p, roleKey); role != nil {
+		*role = members
+		return
+	}
+	updated := make(map[string]models.Role, len(p.Members)+1)
+	for key, role := range p.Members {
+		updated[key] = role
+	}
+	if len(members) == 0 {
+		delete(updated, roleKey)
+	} else {
+		updated[roleKey] = members
+	}
+	p.Members = updated
+}
+
+// projectRoleKeys 返回项目中的所有角色键，内置角色在前，自定义角色按键排序
+func projectRoleKeys(p *models.Project) []string {
+	keys := append([]string{}, builtInRoleKeys...)
+	var custom []string
+	for roleKey := range p.Members {
+		if builtInRole(p, roleKey) == nil {
+			custom = append(custom, roleKey)
+		}
+	}
+	sort.Strings(custom)
+	return append(keys, custom...)
+}
+
+// isProjectMember 判断用户是否为项目任一角色的成员
+func isProjectMember(p *models.Project, userID string) bool {
+	for _, roleKey := range projectRoleKeys(p) {
+		for _, member := range roleMembers(p, roleKey) {
+			if member.UserID == userID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// foldBuiltInMembers 将 members 中提交的内置角色移到对应字段，并保证 members 非空
+func foldBuiltInMembers(p *models.Project) {
+	if p.Members == nil {
+		p.Members = make(map[string]models.Role)
+	}
+	for roleKey, members := range p.Members {
+		if role := builtInRole(p, roleKey); role != nil {
+			*role = members
+			delete(p.Members, roleKey)
+		}
+	}
+}
+
+// loadProjectRoles 读取所有角色定义
+func (h *Handler) loadProjectRoles() ([]models.ProjectRole, error) {
+	rows, err := h.db.Query("SELECT role_key, name, sort_order, built_in FROM project_roles ORDER BY sort_order, role_key")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []models.ProjectRole{}
+	for rows.Next() {
+		var role models.ProjectRole
+		if err := rows.Scan(&role.Key, &role.Name, &role.SortOrder, &role.BuiltIn); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+// projectRoleNames 返回角色键到名称的映射
+func (h *Handler) projectRoleNames() (map[string]string, error) {
+	roles, err := h.loadProjectRoles()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(roles))
+	for _, role := range roles {
+		names[role.Key] = role.Name
+	}
+	return names, nil
+}
+
+// projectRoleDefined 判断角色是否已在角色表中定义
+func (h *Handler) projectRoleDefined(roleKey string) (bool, error) {
+	var exists bool
+	err := h.db.QueryRow("SELECT EXISTS (SELECT 1 FROM project_roles WHERE role_key = $1)", roleKey).Scan(&exists)
+	return exists, err
+}
+
+// checkProjectMemberRoles 校验项目中的自定义角色均已在角色表中定义
+func (h *Handler) checkProjectMemberRoles(p *models.Project) error {
+	if len(p.Members) == 0 {
+		return nil
+	}
+	names, err := h.projectRoleNames()
+	if err != nil {
+		return err
+	}
+	var undefined []string
+	for roleKey := range p.Members {
+		if _, ok := names[roleKey]; !ok {
+			undefined = append(undefined, roleKey)
+		}
+	}
+	if len(undefined) > 0 {
+		sort.Strings(undefined)
+		return &undefinedRolesError{Keys: undefined}
+	}
+	return nil
+}
+
+// undefinedRolesError 项目中使用了未定义的角色
+type undefinedRolesError struct {
+	Keys []string
+}
+
+func (e *undefinedRolesError) Error() string {
+	return "undefined roles: " + strings.Join(e.Keys, ", ")
+}
+
+// respondMemberRolesError 返回角色校验失败的响应，未定义角色为 400，其余为 500
+func respondMemberRolesError(c *gin.Context, err error) {
+	if _, ok := err.(*undefinedRolesError); ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project", "fields": gin.H{"members": err.Error()}})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load roles: " + err.Error()})
+}
+
+// GetProjectRoles 获取所有项目角色
+func (h *Handler) GetProjectRoles(c *gin.Context) {
+	roles, err := h.loadProjectRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// CreateProjectRole 新增自定义项目角色（管理员）
+func (h *Handler) CreateProjectRole(c *gin.Context) {
+	var role models.ProjectRole
+	if err := c.ShouldBindJSON(&role); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	role.Name = strings.TrimSpace(role.Name)
+	if !roleKeyPattern.MatchString(role.Key) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "key must start with a letter and contain only letters, digits or underscores"})
+		return
+	}
+	if role.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	if _, ok := projectPatchers[role.Key]; ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "key conflicts with a project field: " + role.Key})
+		return
+	}
+	role.BuiltIn = false
+
+	result, err := h.db.Exec(`
+		INSERT INTO project_roles (role_key, name, sort_order, built_in)
+		VALUES ($1, $2, $3, FALSE)
+		ON CONFLICT (role_key) DO NOTHING`,
+		role.Key, role.Name, role.SortOrder)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Role already exists"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+// UpdateProjectRole 修改角色名称或排序（管理员），角色键不可修改
+func (h *Handler) UpdateProjectRole(c *gin.Context) {
+	roleKey := c.Param("roleKey")
+
+	var req struct {
+		Name      *string `json:"name"`
+		SortOrder *int    `json:"sortOrder"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name cannot be empty"})
+		return
+	}
+
+	var role models.ProjectRole
+	err := h.db.QueryRow("SELECT role_key, name, sort_order, built_in FROM project_roles WHERE role_key = $1", roleKey).
+		Scan(&role.Key, &role.Name, &role.SortOrder, &role.BuiltIn)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if req.Name != nil {
+		role.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.SortOrder != nil {
+		role.SortOrder = *req.SortOrder
+	}
+
+	_, err = h.db.Exec("UPDATE project_roles SET name = $2, sort_order = $3 WHERE role_key = $1",
+		role.Key, role.Name, role.SortOrder)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// DeleteProjectRole 删除自定义角色（管理员），内置角色及仍有成员或时段的角色不可删除
+func (h *Handler) DeleteProjectRole(c *gin.Context) {
+	roleKey := c.Param("roleKey")
+
+	var builtIn bool
+	err := h.db.QueryRow("SELECT built_in FROM project_roles WHERE role_key = $1", roleKey).Scan(&builtIn)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	if builtIn {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Built-in roles cannot be deleted"})
+		return
+	}
+
+	var inUse bool
+	err = h.db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM projects WHERE members -> $1 IS NOT NULL)
+			OR EXISTS (SELECT 1 FROM time_slots WHERE role_key = $1)`, roleKey).Scan(&inUse)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if inUse {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Role %s still has members", roleKey)})
+		return
+	}
+
+	if _, err := h.db.Exec("DELETE FROM project_roles WHERE role_key = $1", roleKey); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"project-management-backend/internal/models"
+)
+
+func TestSetRoleMembers(t *testing.T) {
+	shared := map[string]models.Role{"designers": {{UserID: "u1"}}}
+	p := models.Project{Members: shared}
+
+	setRoleMembers(&p, "qaTesters", models.Role{{UserID: "u2"}})
+	if len(p.QaTesters) != 1 || len(p.Members) != 1 {
+		t.Errorf("built-in role should be stored in its own field: %+v", p)
+	}
+
+	setRoleMembers(&p, "ops", models.Role{{UserID: "u3"}})
+	if len(roleMembers(&p, "ops")) != 1 {
+		t.Errorf("members = %+v", p.Members)
+	}
+	if _, ok := shared["ops"]; ok {
+		t.Error("setRoleMembers modified a shared members map")
+	}
+
+	setRoleMembers(&p, "designers", nil)
+	if _, ok := p.Members["designers"]; ok {
+		t.Error("custom role without members should be removed")
+	}
+}
+
+func TestProjectRoleKeys(t *testing.T) {
+	p := models.Project{Members: map[string]models.Role{"ops": nil, "designers": nil}}
+	want := append(append([]string{}, builtInRoleKeys...), "designers", "ops")
+	if got := projectRoleKeys(&p); !reflect.DeepEqual(got, want) {
+		t.Errorf("projectRoleKeys = %v, want %v", got, want)
+	}
+}
+
+func TestFoldBuiltInMembers(t *testing.T) {
+	p := models.Project{Members: map[string]models.Role{
+		"qaTesters": {{UserID: "u1"}},
+		"designers": {{UserID: "u2"}},
+	}}
+	foldBuiltInMembers(&p)
+	if len(p.QaTesters) != 1 || p.QaTesters[0].UserID != "u1" {
+		t.Errorf("qaTesters = %+v", p.QaTesters)
+	}
+	if _, ok := p.Members["qaTesters"]; ok || len(p.Members) != 1 {
+		t.Errorf("members = %+v", p.Members)
+	}
+
+	var empty models.Project
+	foldBuiltInMembers(&empty)
+	if empty.Members == nil {
+		t.Error("members should be initialized")
+	}
+}
+
+func TestRoleKeyPattern(t *testing.T) {
+	for key, want := range map[string]bool{
+		"designers": true,
+		"ops_2":     true,
+		"2ops":      false,
+		"ops-team":  false,
+		"":          false,
+	} {
+		if got := roleKeyPattern.MatchString(key); got != want {
+			t.Errorf("roleKeyPattern(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -10,20 +10,20 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(db *sql.DB) *gin.Engine {
+func SetupRouter(db *sql.DB, attachments AttachmentConfig, holidays HolidayCalendar) *gin.Engine {
 	router := gin.Default()
 
 	// 配置CORS
 	config := cors.DefaultConfig()
 	config.AllowAllOrigins = true
 	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With"}
-	config.ExposeHeaders = []string{"Content-Length"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "If-Match"}
+	config.ExposeHeaders = []string{"Content-Length", "X-Total-Count", "X-Next-Cursor", "ETag"}
 	config.AllowCredentials = true
 	router.Use(cors.New(config))
 
 	// 创建处理器
-	handler := NewHandler(db)
+	handler := NewHandler(db, attachments, holidays)
 
 	// API路由组
 	api := router.Group("/api")
@@ -59,9 +59,97 @@ func SetupRouter(db *sql.DB) *gin.Engine {
 		{
 			// 项目相关路由（敏感数据，需要认证）
 			protected.GET("/projects", handler.GetProjects)
+			protected.GET("/projects/trash", handler.GetTrashProjects)
+			protected.GET("/projects/:projectId", handler.GetProject)
 			protected.POST("/projects", handler.CreateProject)
+			protected.POST("/projects/bulk", handler.BulkUpdateProjects)
 			protected.PATCH("/projects/:projectId", handler.UpdateProject)
 			protected.DELETE("/projects/:projectId", handler.DeleteProject)
+			protected.POST("/projects/:projectId/restore", handler.RestoreProject)
+			protected.POST("/projects/:projectId/archive", handler.ArchiveProject)
+			protected.POST("/projects/:projectId/unarchive", handler.UnarchiveProject)
+			protected.POST("/projects/:projectId/clone", handler.CloneProject)
+
+			// 项目附件（文件保存在附件存储后端）
+			protected.GET("/projects/:projectId/attachments", handler.GetProjectAttachments)
+			protected.POST("/projects/:projectId/attachments", handler.UploadProjectAttachment)
+			protected.GET("/projects/:projectId/attachments/:attachmentId/download", handler.DownloadProjectAttachment)
+			protected.DELETE("/projects/:projectId/attachments/:attachmentId", handler.DeleteProjectAttachment)
+
+			// 项目链接（PRD、设计稿、代码仓库等）
+			protected.GET("/projects/:projectId/links", handler.GetProjectLinks)
+			protected.POST("/projects/:projectId/links", handler.CreateProjectLink)
+			protected.PATCH("/projects/:projectId/links/:linkId", handler.UpdateProjectLink)
+			protected.DELETE("/projects/:projectId/links/:linkId", handler.DeleteProjectLink)
+
+			// 项目风险登记
+			protected.GET("/projects/:projectId/risks", handler.GetProjectRisks)
+			protected.POST("/projects/:projectId/risks", handler.CreateProjectRisk)
+			protected.PATCH("/projects/:projectId/risks/:riskId", handler.UpdateProjectRisk)
+			protected.DELETE("/projects/:projectId/risks/:riskId", handler.DeleteProjectRisk)
+
+			// 项目健康度历史
+			protected.GET("/projects/:projectId/health-history", handler.GetProjectHealthHistory)
+
+			// 项目标签
+			protected.PUT("/projects/:projectId/labels", handler.SetProjectLabels)
+			protected.POST("/projects/:projectId/labels/:labelId", handler.AddProjectLabel)
+			protected.DELETE("/projects/:projectId/labels/:labelId", handler.RemoveProjectLabel)
+
+			// 项目任务（支持一层子任务）
+			protected.GET("/projects/:projectId/tasks", handler.GetProjectTasks)
+			protected.POST("/projects/:projectId/tasks", handler.CreateProjectTask)
+			protected.PATCH("/projects/:projectId/tasks/:taskId", handler.UpdateProjectTask)
+			protected.DELETE("/projects/:projectId/tasks/:taskId", handler.DeleteProjectTask)
+
+			// 项目依赖关系
+			protected.GET("/projects/:projectId/dependencies", handler.GetProjectDependencies)
+			protected.POST("/projects/:projectId/dependencies", handler.CreateProjectDependency)
+			protected.DELETE("/projects/:projectId/dependencies/:dependencyId", handler.DeleteProjectDependency)
+
+			// 项目里程碑
+			protected.GET("/projects/:projectId/milestones", handler.GetProjectMilestones)
+			protected.POST("/projects/:projectId/milestones", handler.CreateProjectMilestone)
+			protected.PATCH("/projects/:projectId/milestones/:milestoneId", handler.UpdateProjectMilestone)
+			protected.DELETE("/projects/:projectId/milestones/:milestoneId", handler.DeleteProjectMilestone)
+
+			// 成员时段（按项目、角色、成员单独维护）
+			protected.GET("/projects/:projectId/roles/:role/members/:userId/time-slots", handler.GetMemberTimeSlots)
+			protected.POST("/projects/:projectId/roles/:role/members/:userId/time-slots", handler.CreateMemberTimeSlot)
+			protected.PATCH("/projects/:projectId/roles/:role/members/:userId/time-slots/:slotId", handler.UpdateMemberTimeSlot)
+			protected.DELETE("/projects/:projectId/roles/:role/members/:userId/time-slots/:slotId", handler.DeleteMemberTimeSlot)
+
+			// 工作量预估（按角色，人天）与时段实际排期对比
+			protected.GET("/projects/:projectId/effort", handler.GetProjectEffort)
+			protected.PUT("/projects/:projectId/effort/estimates/:role", handler.SetProjectEffortEstimate)
+			protected.DELETE("/projects/:projectId/effort/estimates/:role", handler.DeleteProjectEffortEstimate)
+
+			// 报表
+			protected.GET("/reports/capacity", handler.GetCapacityReport)
+			protected.GET("/reports/risks", handler.GetRiskReport)
+			protected.GET("/reports/health-worsened", handler.GetHealthWorsenedReport)
+
+			// 项目状态流转定义（修改需要管理员权限）
+			protected.GET("/workflow", handler.GetStatusWorkflow)
+			protected.PUT("/workflow", middleware.RequireRole("admin"), handler.UpdateStatusWorkflow)
+
+			// 标签目录（增删改需要管理员权限）
+			protected.GET("/labels", handler.GetLabels)
+			protected.POST("/labels", middleware.RequireRole("admin"), handler.CreateLabel)
+			protected.PATCH("/labels/:labelId", middleware.RequireRole("admin"), handler.UpdateLabel)
+			protected.DELETE("/labels/:labelId", middleware.RequireRole("admin"), handler.DeleteLabel)
+
+			// 自定义字段定义（增删改需要管理员权限）
+			protected.GET("/custom-fields", handler.GetCustomFields)
+			protected.POST("/custom-fields", middleware.RequireRole("admin"), handler.CreateCustomField)
+			protected.PATCH("/custom-fields/:fieldKey", middleware.RequireRole("admin"), handler.UpdateCustomField)
+			protected.DELETE("/custom-fields/:fieldKey", middleware.RequireRole("admin"), handler.DeleteCustomField)
+
+			// 项目角色定义（增删改需要管理员权限）
+			protected.GET("/roles", handler.GetProjectRoles)
+			protected.POST("/roles", middleware.RequireRole("admin"), handler.CreateProjectRole)
+			protected.PATCH("/roles/:roleKey", middleware.RequireRole("admin"), handler.UpdateProjectRole)
+			protected.DELETE("/roles/:roleKey", middleware.RequireRole("admin"), handler.DeleteProjectRole)
 
 			// OKR相关路由（敏感数据，需要认证）
 			protected.GET("/okr-sets", handler.GetOkrSets)
@@ -0,0 +1,356 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// taskDoneStatus 任务完成状态，用于统计完成率
+const taskDoneStatus = "已完成"
+
+// taskColumns 构成任务的 project_tasks 列，与 scanTask 的扫描顺序一致
+const taskColumns = "id, parent_id, title, assignee_id, status, due_date, created_at, updated_at"
+
+// scanTask 扫描一行任务数据，日期统一为 YYYY-MM-DD
+func scanTask(row rowScanner) (models.Task, error) {
+	var t models.Task
+	var dueDate *string
+	if err := row.Scan(&t.ID, &t.ParentID, &t.Title, &t.AssigneeID, &t.Status, &dueDate, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return t, err
+	}
+	if dueDate != nil {
+		date := derefDate(dueDate)
+		t.DueDate = &date
+	}
+	return t, nil
+}
+
+// patchOptionalID 可选的ID字段，空字符串与 null 均视为清空
+func patchOptionalID(dst **string, raw json.RawMessage) error {
+	if err := patchOptionalString(dst, raw); err != nil {
+		return err
+	}
+	if *dst != nil && strings.TrimSpace(**dst) == "" {
+		*dst = nil
+	}
+	return nil
+}
+
+// taskPatchers 任务支持的 merge patch 字段
+var taskPatchers = map[string]func(t *models.Task, raw json.RawMessage) error{
+	"title": func(t *models.Task, raw json.RawMessage) error {
+		if err := patchRequiredString(&t.Title, raw); err != nil {
+			return err
+		}
+		t.Title = strings.TrimSpace(t.Title)
+		return nil
+	},
+	"status": func(t *models.Task, raw json.RawMessage) error {
+		if err := patchRequiredString(&t.Status, raw); err != nil {
+			return err
+		}
+		for _, status := range models.TaskStatuses {
+			if t.Status == status {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(models.TaskStatuses, ", "))
+	},
+	"assigneeId": func(t *models.Task, raw json.RawMessage) error {
+		return patchOptionalID(&t.AssigneeID, raw)
+	},
+	"dueDate": func(t *models.Task, raw json.RawMessage) error {
+		return patchOptionalDate(&t.DueDate, raw)
+	},
+	"parentId": func(t *models.Task, raw json.RawMessage) error {
+		return patchOptionalID(&t.ParentID, raw)
+	},
+}
+
+// checkTaskRelations 校验负责人为项目成员，父任务属于同一项目且只允许一层子任务
+func (h *Handler) checkTaskRelations(project *models.Project, task *models.Task) (map[string]string, error) {
+	fieldErrors := make(map[string]string)
+	if task.AssigneeID != nil && !isProjectMember(project, *task.AssigneeID) {
+		fieldErrors["assigneeId"] = "must be a member of the project"
+	}
+	if task.ParentID == nil {
+		return fieldErrors, nil
+	}
+
+	if *task.ParentID == task.ID {
+		fieldErrors["parentId"] = "cannot be the task itself"
+		return fieldErrors, nil
+	}
+	var grandparentID *string
+	err := h.db.QueryRow("SELECT parent_id FROM project_tasks WHERE id = $1 AND project_id = $2", *task.ParentID, project.ID).
+		Scan(&grandparentID)
+	if err == sql.ErrNoRows {
+		fieldErrors["parentId"] = "parent task not found in project"
+		return fieldErrors, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if grandparentID != nil {
+		fieldErrors["parentId"] = "subtasks cannot have subtasks"
+		return fieldErrors, nil
+	}
+
+	var hasSubtasks bool
+	if err := h.db.QueryRow("SELECT EXISTS (SELECT 1 FROM project_tasks WHERE parent_id = $1)", task.ID).Scan(&hasSubtasks); err != nil {
+		return nil, err
+	}
+	if hasSubtasks {
+		fieldErrors["parentId"] = "a task with subtasks cannot become a subtask"
+	}
+	return fieldErrors, nil
+}
+
+// loadTaskSummaries 批量统计项目的任务完成率，只统计没有子任务的任务
+func (h *Handler) loadTaskSummaries(projects []models.Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+
+	projectIDs := make([]string, len(projects))
+	for i, project := range projects {
+		projectIDs[i] = project.ID
+	}
+
+	rows, err := h.db.Query(`
+		SELECT t.project_id, COUNT(*), SUM(CASE WHEN t.status = $2 THEN 1 ELSE 0 END)
+		FROM project_tasks t
+		WHERE t.project_id = ANY($1)
+			AND NOT EXISTS (SELECT 1 FROM project_tasks s WHERE s.parent_id = t.id)
+		GROUP BY t.project_id`, pq.Array(projectIDs), taskDoneStatus)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	summaries := make(map[string]*models.TaskSummary)
+	for rows.Next() {
+		var projectID string
+		summary := &models.TaskSummary{}
+		if err := rows.Scan(&projectID, &summary.Total, &summary.Done); err != nil {
+			return err
+		}
+		if summary.Total > 0 {
+			summary.CompletionPercent = summary.Done * 100 / summary.Total
+		}
+		summaries[projectID] = summary
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range projects {
+		if summary, ok := summaries[projects[i].ID]; ok {
+			projects[i].TaskSummary = summary
+		} else {
+			projects[i].TaskSummary = &models.TaskSummary{}
+		}
+	}
+	return nil
+}
+
+// loadTaskProject 读取任务所属的未删除项目（含成员），失败时已写入响应
+func (h *Handler) loadTaskProject(c *gin.Context, projectID string) (models.Project, bool) {
+	project, err := scanProject(h.db.QueryRow("SELECT "+projectColumns+" FROM projects WHERE id = $1 AND deleted_at IS NULL", projectID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return project, false
+	}
+	return project, true
+}
+
+// loadTask 读取项目的单个任务
+func (h *Handler) loadTask(projectID, taskID string) (models.Task, error) {
+	return scanTask(h.db.QueryRow(
+		"SELECT "+taskColumns+" FROM project_tasks WHERE id = $1 AND project_id = $2", taskID, projectID))
+}
+
+// GetProjectTasks 获取项目任务（平铺列表，子任务通过 parentId 关联）
+// 支持按 status、assigneeId 筛选
+func (h *Handler) GetProjectTasks(c *gin.Context) {
+	projectID := c.Param("projectId")
+	if _, ok := h.loadTaskProject(c, projectID); !ok {
+		return
+	}
+
+	query := "SELECT " + taskColumns + " FROM project_tasks WHERE project_id = $1"
+	args := []interface{}{projectID}
+	if status := c.Query("status"); status != "" {
+		args = append(args, status)
+		query += " AND status = $" + strconv.Itoa(len(args))
+	}
+	if assigneeID := c.Query("assigneeId"); assigneeID != "" {
+		args = append(args, assigneeID)
+		query += " AND assignee_id = $" + strconv.Itoa(len(args))
+	}
+	query += " ORDER BY created_at, id"
+
+	rows, err := h.db.Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	tasks := []models.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// CreateProjectTask 新增任务，title 必填，status 默认为未开始
+func (h *Handler) CreateProjectTask(c *gin.Context) {
+	projectID := c.Param("projectId")
+	patch, ok := bindMergePatch(c, "Task")
+	if !ok {
+		return
+	}
+
+	task := models.Task{ID: newID("task"), Status: models.TaskStatuses[0]}
+	fieldErrors := applyPatch(&task, taskPatchers, patch)
+	if _, ok := patch["title"]; !ok {
+		fieldErrors["title"] = "is required"
+	}
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task", "fields": fieldErrors})
+		return
+	}
+
+	project, ok := h.loadTaskProject(c, projectID)
+	if !ok {
+		return
+	}
+	fieldErrors, err := h.checkTaskRelations(&project, &task)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task", "fields": fieldErrors})
+		return
+	}
+
+	_, err = h.db.Exec(`
+		INSERT INTO project_tasks (id, project_id, parent_id, title, assignee_id, status, due_date)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		task.ID, projectID, task.ParentID, task.Title, task.AssigneeID, task.Status, task.DueDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save task: " + err.Error()})
+		return
+	}
+
+	saved, err := h.loadTask(projectID, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, saved)
+}
+
+// UpdateProjectTask 更新任务（JSON Merge Patch）
+func (h *Handler) UpdateProjectTask(c *gin.Context) {
+	projectID := c.Param("projectId")
+	taskID := c.Param("taskId")
+	patch, ok := bindMergePatch(c, "Task")
+	if !ok {
+		return
+	}
+
+	project, ok := h.loadTaskProject(c, projectID)
+	if !ok {
+		return
+	}
+	task, err := h.loadTask(projectID, taskID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	fieldErrors := applyPatch(&task, taskPatchers, patch)
+	if len(fieldErrors) == 0 {
+		if fieldErrors, err = h.checkTaskRelations(&project, &task); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	// 负责人未修改时不因其已离开项目而拒绝其他字段的修改
+	if _, ok := patch["assigneeId"]; !ok {
+		delete(fieldErrors, "assigneeId")
+	}
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task", "fields": fieldErrors})
+		return
+	}
+
+	_, err = h.db.Exec(`
+		UPDATE project_tasks
+		SET parent_id = $1, title = $2, assignee_id = $3, status = $4, due_date = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6 AND project_id = $7`,
+		task.ParentID, task.Title, task.AssigneeID, task.Status, task.DueDate, taskID, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save task: " + err.Error()})
+		return
+	}
+
+	saved, err := h.loadTask(projectID, taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, saved)
+}
+
+// DeleteProjectTask 删除任务及其子任务
+func (h *Handler) DeleteProjectTask(c *gin.Context) {
+	projectID := c.Param("projectId")
+	taskID := c.Param("taskId")
+
+	if !h.checkProjectExists(c, h.db, projectID) {
+		return
+	}
+
+	result, err := h.db.Exec(
+		"DELETE FROM project_tasks WHERE project_id = $1 AND (id = $2 OR parent_id = $2)", projectID, taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
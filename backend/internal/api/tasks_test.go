@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"project-management-backend/internal/models"
+)
+
+func TestApplyTaskPatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		patch     string
+		wantError string
+		check     func(t *testing.T, task models.Task)
+	}{
+		{name: "trim title", patch: `{"title":"  写文档 "}`, check: func(t *testing.T, task models.Task) {
+			if task.Title != "写文档" {
+				t.Errorf("title = %q", task.Title)
+			}
+		}},
+		{name: "blank ids are cleared", patch: `{"assigneeId":" ","parentId":""}`, check: func(t *testing.T, task models.Task) {
+			if task.AssigneeID != nil || task.ParentID != nil {
+				t.Errorf("assignee = %v, parent = %v", task.AssigneeID, task.ParentID)
+			}
+		}},
+		{name: "due date normalized", patch: `{"dueDate":"2026-10-20T00:00:00Z"}`, check: func(t *testing.T, task models.Task) {
+			if derefString(task.DueDate) != "2026-10-20" {
+				t.Errorf("dueDate = %v", task.DueDate)
+			}
+		}},
+		{name: "unknown status", patch: `{"status":"暂停"}`, wantError: "status"},
+		{name: "title cannot be cleared", patch: `{"title":null}`, wantError: "title"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatal(err)
+			}
+			assignee, parent := "u1", "t0"
+			task := models.Task{ID: "t1", Title: "任务", Status: models.TaskStatuses[0], AssigneeID: &assignee, ParentID: &parent}
+			fieldErrors := applyPatch(&task, taskPatchers, patch)
+			if tt.wantError != "" {
+				if _, ok := fieldErrors[tt.wantError]; !ok {
+					t.Errorf("missing error for %s: %v", tt.wantError, fieldErrors)
+				}
+				return
+			}
+			if len(fieldErrors) > 0 {
+				t.Fatalf("unexpected errors %v", fieldErrors)
+			}
+			tt.check(t, task)
+		})
+	}
+}
+
+func TestCheckTaskRelationsAssignee(t *testing.T) {
+	project := &models.Project{
+		QaTesters: models.Role{{UserID: "u1"}},
+		Members:   map[string]models.Role{"designers": {{UserID: "u2"}}},
+	}
+	for assignee, wantError := range map[string]bool{"u1": false, "u2": false, "u3": true} {
+		id := assignee
+		fieldErrors, err := (&Handler{}).checkTaskRelations(project, &models.Task{ID: "t1", AssigneeID: &id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := fieldErrors["assigneeId"]; ok != wantError {
+			t.Errorf("assignee %s: errors = %v", assignee, fieldErrors)
+		}
+	}
+}
+
+func TestLoadTaskSummaries(t *testing.T) {
+	db := openTestDB(t)
+	h := &Handler{db: db}
+	withTasks, withoutTasks := "test-task-summary", "test-task-summary-empty"
+	insertTestProject(t, db, withTasks, withTasks, "P1", "开发中")
+	insertTestProject(t, db, withoutTasks, withoutTasks, "P1", "开发中")
+	t.Cleanup(func() { db.Exec("DELETE FROM project_tasks WHERE project_id = $1", withTasks) })
+
+	// 父任务有子任务，不计入统计；剩余 3 个任务完成 1 个
+	for _, task := range []struct{ id, parentID, status string }{
+		{"test-task-parent", "", "进行中"},
+		{"test-task-child-1", "test-task-parent", taskDoneStatus},
+		{"test-task-child-2", "test-task-parent", "进行中"},
+		{"test-task-single", "", "未开始"},
+	} {
+		var parentID interface{}
+		if task.parentID != "" {
+			parentID = task.parentID
+		}
+		_, err := db.Exec(`INSERT INTO project_tasks (id, project_id, parent_id, title, status) VALUES ($1, $2, $3, $1, $4)`,
+			task.id, withTasks, parentID, task.status)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projects := []models.Project{{ID: withTasks}, {ID: withoutTasks}}
+	if err := h.loadTaskSummaries(projects); err != nil {
+		t.Fatal(err)
+	}
+	if got := *projects[0].TaskSummary; got.Total != 3 || got.Done != 1 || got.CompletionPercent != 33 {
+		t.Errorf("summary = %+v, want 1/3 done (33%%)", got)
+	}
+	if got := projects[1].TaskSummary; got == nil || got.Total != 0 {
+		t.Errorf("project without tasks summary = %+v", got)
+	}
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 时段警告代码
+const (
+	slotStartsBeforeProposal = "SLOT_STARTS_BEFORE_PROPOSED_DATE"
+	slotEndsAfterLaunch      = "SLOT_ENDS_AFTER_LAUNCH_DATE"
+)
+
+// standardHoursPerWeek 满负荷对应的每周工时，用于由工时推算投入比例
+const standardHoursPerWeek = 40
+
+// normalizeTimeSlot 校验时段日期格式并统一为 YYYY-MM-DD，结束日期不能早于开始日期
+// 未设置投入比例时按每周工时推算，均未设置则为 100%（已有时段见 inheritSlotAllocation）
+func normalizeTimeSlot(slot *models.TimeSlot) error {
+	if slot.HoursPerWeek != nil && (*slot.HoursPerWeek <= 0 || *slot.HoursPerWeek > 168) {
+		return fmt.Errorf("hoursPerWeek must be greater than 0 and at most 168")
+	}
+	if slot.AllocationPercent == 0 {
+		slot.AllocationPercent = fullAllocation
+		if slot.HoursPerWeek != nil {
+			slot.AllocationPercent = int(math.Round(*slot.HoursPerWeek / standardHoursPerWeek * fullAllocation))
+			if slot.AllocationPercent < 1 {
+				slot.AllocationPercent = 1
+			}
+			if slot.AllocationPercent > fullAllocation {
+				slot.AllocationPercent = fullAllocation
+			}
+		}
+	}
+	if slot.AllocationPercent < 1 || slot.AllocationPercent > fullAllocation {
+		return fmt.Errorf("allocationPercent must be between 1 and 100")
+	}
+
+	for _, date := range []*string{&slot.StartDate, &slot.EndDate} {
+		if *date == "" {
+			continue
+		}
+		normalized, ok := normalizeDate(*date)
+		if !ok {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", *date)
+		}
+		*date = normalized
+	}
+	if slot.StartDate != "" && slot.EndDate != "" && slot.EndDate < slot.StartDate {
+		return fmt.Errorf("endDate %s is before startDate %s", slot.EndDate, slot.StartDate)
+	}
+	return nil
+}
+
+// inheritSlotAllocation 未提交投入比例和每周工时的时段沿用 existing 中同ID时段的设置，
+// 避免不识别这两个字段的旧客户端整体提交团队时把部分投入的时段重置为 100%
+func inheritSlotAllocation(role, existing models.Role) {
+	previous := make(map[string]models.TimeSlot)
+	for _, member := range existing {
+		for _, slot := range member.TimeSlots {
+			if slot.ID != "" {
+				previous[slot.ID] = slot
+			}
+		}
+	}
+	for i := range role {
+		for j := range role[i].TimeSlots {
+			slot := &role[i].TimeSlots[j]
+			if slot.AllocationPercent != 0 || slot.HoursPerWeek != nil {
+				continue
+			}
+			if old, ok := previous[slot.ID]; ok && slot.ID != "" {
+				slot.AllocationPercent = old.AllocationPercent
+				slot.HoursPerWeek = old.HoursPerWeek
+			}
+		}
+	}
+}
+
+// checkSlotOverlap 检查同一成员的时段是否重叠（起止日期均包含在内）
+// 只比较起止日期都已填写的时段
+func checkSlotOverlap(slots []models.TimeSlot) error {
+	for i := range slots {
+		a := slots[i]
+		if a.StartDate == "" || a.EndDate == "" {
+			continue
+		}
+		for j := i + 1; j < len(slots); j++ {
+			b := slots[j]
+			if b.StartDate == "" || b.EndDate == "" {
+				continue
+			}
+			if a.StartDate <= b.EndDate && b.StartDate <= a.EndDate {
+				return fmt.Errorf("time slots %s~%s and %s~%s overlap", a.StartDate, a.EndDate, b.StartDate, b.EndDate)
+			}
+		}
+	}
+	return nil
+}
+
+// validateRoleTimeSlots 校验角色中每个成员的时段，日期会被规范化
+func validateRoleTimeSlots(role models.Role) error {
+	for i := range role {
+		member := &role[i]
+		for j := range member.TimeSlots {
+			if err := normalizeTimeSlot(&member.TimeSlots[j]); err != nil {
+				return fmt.Errorf("member %s: %v", member.UserID, err)
+			}
+		}
+		if err := checkSlotOverlap(member.TimeSlots); err != nil {
+			return fmt.Errorf("member %s: %v", member.UserID, err)
+		}
+	}
+	return nil
+}
+
+// validateProjectTimeSlots 校验项目所有角色的时段，返回逐字段的错误
+func validateProjectTimeSlots(project *models.Project) map[string]string {
+	fieldErrors := make(map[string]string)
+	for _, roleKey := range projectRoleKeys(project) {
+		if err := validateRoleTimeSlots(roleMembers(project, roleKey)); err != nil {
+			fieldErrors[roleKey] = err.Error()
+		}
+	}
+	return fieldErrors
+}
+
+// slotWindowWarnings 检查时段是否超出项目提出时间至上线时间的范围
+func slotWindowWarnings(project *models.Project, roleKey, userID string, slot models.TimeSlot) []models.TimeSlotWarning {
+	var warnings []models.TimeSlotWarning
+	proposedDate, launchDate := derefDate(project.ProposalDate), derefDate(project.LaunchDate)
+
+	if proposedDate != "" && slot.StartDate != "" && slot.StartDate < proposedDate {
+		warnings = append(warnings, models.TimeSlotWarning{
+			Code:    slotStartsBeforeProposal,
+			Role:    roleKey,
+			UserID:  userID,
+			SlotID:  slot.ID,
+			Message: fmt.Sprintf("startDate %s is before the project's proposedDate %s", slot.StartDate, proposedDate),
+		})
+	}
+	if launchDate != "" && slot.EndDate != "" && slot.EndDate > launchDate {
+		warnings = append(warnings, models.TimeSlotWarning{
+			Code:    slotEndsAfterLaunch,
+			Role:    roleKey,
+			UserID:  userID,
+			SlotID:  slot.ID,
+			Message: fmt.Sprintf("endDate %s is after the project's launchDate %s", slot.EndDate, launchDate),
+		})
+	}
+	return warnings
+}
+
+// projectSlotWarnings 汇总项目所有时段的警告
+func projectSlotWarnings(project *models.Project) []models.TimeSlotWarning {
+	var warnings []models.TimeSlotWarning
+	for _, roleKey := range projectRoleKeys(project) {
+		for _, member := range roleMembers(project, roleKey) {
+			for _, slot := range member.TimeSlots {
+				warnings = append(warnings, slotWindowWarnings(project, roleKey, member.UserID, slot)...)
+			}
+		}
+	}
+	return warnings
+}
+
+// strictSlotValidation 请求是否开启严格模式（?strict=true），严格模式下警告视为错误
+func strictSlotValidation(c *gin.Context) bool {
+	return c.Query("strict") == "true"
+}
+
+// respondSlotWarningsRejected 严格模式下拒绝带警告的时段
+func respondSlotWarningsRejected(c *gin.Context, warnings []models.TimeSlotWarning) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":    "Time slots fall outside the project's date range",
+		"warnings": warnings,
+	})
+}
+
+// projectResponse 项目响应，附带时段警告及依赖关系警告
+type projectResponse struct {
+	models.Project
+	Warnings           []models.TimeSlotWarning   `json:"warnings,omitempty"`
+	DependencyWarnings []models.DependencyWarning `json:"dependencyWarnings,omitempty"`
+}
+
+// timeSlotResponse 时段响应，附带时段警告
+type timeSlotResponse struct {
+	models.TimeSlot
+	Warnings []models.TimeSlotWarning `json:"warnings,omitempty"`
+}
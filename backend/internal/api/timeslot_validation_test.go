@@ -0,0 +1,135 @@
+package api
+
+import (
+	"testing"
+
+	"project-management-backend/internal/models"
+)
+
+func TestNormalizeTimeSlot(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name           string
+		slot           models.TimeSlot
+		wantErr        bool
+		wantStart      string
+		wantEnd        string
+		wantAllocation int
+	}{
+		{name: "plain dates", slot: models.TimeSlot{StartDate: "2026-10-01", EndDate: "2026-10-31"},
+			wantStart: "2026-10-01", wantEnd: "2026-10-31", wantAllocation: 100},
+		{name: "open-ended", slot: models.TimeSlot{StartDate: "2026-10-01"}, wantStart: "2026-10-01", wantAllocation: 100},
+		{name: "RFC3339 dates", slot: models.TimeSlot{StartDate: "2026-10-01T00:00:00Z", EndDate: "2026-10-02T08:00:00Z"},
+			wantStart: "2026-10-01", wantEnd: "2026-10-02", wantAllocation: 100},
+		{name: "invalid date", slot: models.TimeSlot{StartDate: "2026/10/01"}, wantErr: true},
+		{name: "end before start", slot: models.TimeSlot{StartDate: "2026-10-02", EndDate: "2026-10-01"}, wantErr: true},
+		{name: "same start and end", slot: models.TimeSlot{StartDate: "2026-10-01", EndDate: "2026-10-01"},
+			wantStart: "2026-10-01", wantEnd: "2026-10-01", wantAllocation: 100},
+		{name: "defaults to full allocation", slot: models.TimeSlot{}, wantAllocation: 100},
+		{name: "keeps explicit allocation", slot: models.TimeSlot{AllocationPercent: 50}, wantAllocation: 50},
+		{name: "allocation from hours", slot: models.TimeSlot{HoursPerWeek: f(20)}, wantAllocation: 50},
+		{name: "small hours round up to 1", slot: models.TimeSlot{HoursPerWeek: f(0.1)}, wantAllocation: 1},
+		{name: "overtime capped at 100", slot: models.TimeSlot{HoursPerWeek: f(60)}, wantAllocation: 100},
+		{name: "explicit allocation wins over hours", slot: models.TimeSlot{AllocationPercent: 30, HoursPerWeek: f(40)}, wantAllocation: 30},
+		{name: "zero hours", slot: models.TimeSlot{HoursPerWeek: f(0)}, wantErr: true},
+		{name: "too many hours", slot: models.TimeSlot{HoursPerWeek: f(169)}, wantErr: true},
+		{name: "allocation above 100", slot: models.TimeSlot{AllocationPercent: 101}, wantErr: true},
+		{name: "negative allocation", slot: models.TimeSlot{AllocationPercent: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slot := tt.slot
+			err := normalizeTimeSlot(&slot)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if slot.AllocationPercent != tt.wantAllocation {
+				t.Errorf("allocation = %d, want %d", slot.AllocationPercent, tt.wantAllocation)
+			}
+			if slot.StartDate != tt.wantStart || slot.EndDate != tt.wantEnd {
+				t.Errorf("dates = %s~%s, want %s~%s", slot.StartDate, slot.EndDate, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestInheritSlotAllocation(t *testing.T) {
+	hours := 16.0
+	existing := models.Role{{UserID: "u1", TimeSlots: []models.TimeSlot{
+		{ID: "s1", AllocationPercent: 40, HoursPerWeek: &hours},
+		{ID: "s2", AllocationPercent: 60},
+	}}}
+	role := models.Role{{UserID: "u1", TimeSlots: []models.TimeSlot{
+		{ID: "s1"},
+		{ID: "s2", AllocationPercent: 80},
+		{ID: "s3"},
+		{},
+	}}}
+	inheritSlotAllocation(role, existing)
+
+	slots := role[0].TimeSlots
+	if slots[0].AllocationPercent != 40 || slots[0].HoursPerWeek == nil || *slots[0].HoursPerWeek != 16 {
+		t.Errorf("existing slot did not inherit allocation: %+v", slots[0])
+	}
+	if slots[1].AllocationPercent != 80 {
+		t.Errorf("submitted allocation was overwritten: %d", slots[1].AllocationPercent)
+	}
+	if slots[2].AllocationPercent != 0 || slots[3].AllocationPercent != 0 {
+		t.Errorf("new slots should be left for normalizeTimeSlot: %+v %+v", slots[2], slots[3])
+	}
+}
+
+func TestCheckSlotOverlap(t *testing.T) {
+	tests := []struct {
+		name    string
+		slots   []models.TimeSlot
+		wantErr bool
+	}{
+		{"disjoint", []models.TimeSlot{{StartDate: "2026-10-01", EndDate: "2026-10-10"}, {StartDate: "2026-10-11", EndDate: "2026-10-20"}}, false},
+		{"shared boundary day", []models.TimeSlot{{StartDate: "2026-10-01", EndDate: "2026-10-10"}, {StartDate: "2026-10-10", EndDate: "2026-10-20"}}, true},
+		{"contained", []models.TimeSlot{{StartDate: "2026-10-01", EndDate: "2026-10-31"}, {StartDate: "2026-10-05", EndDate: "2026-10-06"}}, true},
+		{"open-ended slot ignored", []models.TimeSlot{{StartDate: "2026-10-01"}, {StartDate: "2026-10-05", EndDate: "2026-10-06"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkSlotOverlap(tt.slots); (err != nil) != tt.wantErr {
+				t.Errorf("checkSlotOverlap error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProjectSlotWarnings(t *testing.T) {
+	s := func(v string) *string { return &v }
+	project := models.Project{
+		ProposalDate: s("2026-10-01"),
+		LaunchDate:   s("2026-10-31"),
+		BackendDevelopers: models.Role{{UserID: "u1", TimeSlots: []models.TimeSlot{
+			{ID: "inside", StartDate: "2026-10-01", EndDate: "2026-10-31"},
+			{ID: "early", StartDate: "2026-09-30", EndDate: "2026-10-10"},
+			{ID: "late", StartDate: "2026-10-20", EndDate: "2026-11-01"},
+		}}},
+	}
+	var got []string
+	for _, w := range projectSlotWarnings(&project) {
+		if w.Role != "backendDevelopers" || w.UserID != "u1" {
+			t.Errorf("warning target = %s/%s", w.Role, w.UserID)
+		}
+		got = append(got, w.SlotID+":"+w.Code)
+	}
+	want := []string{"early:" + slotStartsBeforeProposal, "late:" + slotEndsAfterLaunch}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("warnings = %v, want %v", got, want)
+	}
+
+	project.ProposalDate, project.LaunchDate = nil, nil
+	if warnings := projectSlotWarnings(&project); len(warnings) != 0 {
+		t.Errorf("project without dates produced warnings %+v", warnings)
+	}
+}
@@ -0,0 +1,441 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"project-management-backend/internal/middleware"
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// timeSlotColumns 构成时段的 time_slots 列，与 scanTimeSlot 的扫描顺序一致
+const timeSlotColumns = "id, start_date, end_date, description, allocation_percent, hours_per_week"
+
+// scanTimeSlot 扫描一行时段数据，日期统一为 YYYY-MM-DD
+// extra 用于接收 timeSlotColumns 之后的附加列
+func scanTimeSlot(row rowScanner, extra ...interface{}) (models.TimeSlot, error) {
+	var slot models.TimeSlot
+	var startDate, endDate *string
+	dest := append([]interface{}{
+		&slot.ID, &startDate, &endDate, &slot.Description, &slot.AllocationPercent, &slot.HoursPerWeek,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return slot, err
+	}
+	slot.StartDate = derefDate(startDate)
+	slot.EndDate = derefDate(endDate)
+	return slot, nil
+}
+
+// nullableDate 空日期写入数据库时存为 NULL
+func nullableDate(date string) interface{} {
+	if date == "" {
+		return nil
+	}
+	return date
+}
+
+// timeSlotPatchers 时段支持的 merge patch 字段
+var timeSlotPatchers = map[string]func(slot *models.TimeSlot, raw json.RawMessage) error{
+	"startDate": func(slot *models.TimeSlot, raw json.RawMessage) error {
+		return patchSlotDate(&slot.StartDate, raw)
+	},
+	"endDate": func(slot *models.TimeSlot, raw json.RawMessage) error {
+		return patchSlotDate(&slot.EndDate, raw)
+	},
+	"description": func(slot *models.TimeSlot, raw json.RawMessage) error {
+		return patchOptionalString(&slot.Description, raw)
+	},
+	"allocationPercent": func(slot *models.TimeSlot, raw json.RawMessage) error {
+		// null 恢复为默认的 100%
+		if isJSONNull(raw) {
+			slot.AllocationPercent = fullAllocation
+			return nil
+		}
+		if err := json.Unmarshal(raw, &slot.AllocationPercent); err != nil {
+			return fmt.Errorf("must be an integer between 1 and 100")
+		}
+		return nil
+	},
+	"hoursPerWeek": func(slot *models.TimeSlot, raw json.RawMessage) error {
+		if isJSONNull(raw) {
+			slot.HoursPerWeek = nil
+			return nil
+		}
+		var hours float64
+		if err := json.Unmarshal(raw, &hours); err != nil {
+			return fmt.Errorf("must be a number or null")
+		}
+		slot.HoursPerWeek = &hours
+		return nil
+	},
+}
+
+func patchSlotDate(dst *string, raw json.RawMessage) error {
+	var date *string
+	if err := patchOptionalDate(&date, raw); err != nil {
+		return err
+	}
+	*dst = derefString(date)
+	return nil
+}
+
+// timeSlotTarget 时段接口路径中的项目、角色与成员
+type timeSlotTarget struct {
+	ProjectID string
+	RoleKey   string
+	UserID    string
+}
+
+func newTimeSlotTarget(c *gin.Context) timeSlotTarget {
+	return timeSlotTarget{
+		ProjectID: c.Param("projectId"),
+		RoleKey:   c.Param("role"),
+		UserID:    c.Param("userId"),
+	}
+}
+
+// findMember 在项目的角色中查找成员
+func (t timeSlotTarget) findMember(project *models.Project) *models.TeamMember {
+	role := roleMembers(project, t.RoleKey)
+	for i := range role {
+		if role[i].UserID == t.UserID {
+			return &role[i]
+		}
+	}
+	return nil
+}
+
+// checkTimeSlotRole 校验路径中的角色已在角色表中定义，失败时已写入响应
+func (h *Handler) checkTimeSlotRole(c *gin.Context, roleKey string) bool {
+	defined, err := h.projectRoleDefined(roleKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load roles: " + err.Error()})
+		return false
+	}
+	if !defined {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role: " + roleKey})
+		return false
+	}
+	return true
+}
+
+// lockTimeSlotMember 在事务中锁定项目并校验成员属于该角色、If-Match 版本匹配
+// 校验失败时已写入响应，返回 false
+func (h *Handler) lockTimeSlotMember(c *gin.Context, tx *sql.Tx, target timeSlotTarget) (models.Project, bool) {
+	if !h.checkTimeSlotRole(c, target.RoleKey) {
+		return models.Project{}, false
+	}
+
+	project, err := scanProject(tx.QueryRow(
+		"SELECT "+projectColumns+" FROM projects WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", target.ProjectID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return project, false
+	}
+
+	if !ifMatchSatisfied(c.GetHeader("If-Match"), project.Version) {
+		tx.Rollback()
+		h.respondVersionConflict(c, target.ProjectID)
+		return project, false
+	}
+
+	h.initializeEmptyTimeSlots(&project)
+	if err := h.loadTimeSlots(tx, &project); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load time slots: " + err.Error()})
+		return project, false
+	}
+
+	if target.findMember(&project) == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found in role"})
+		return project, false
+	}
+	return project, true
+}
+
+// saveTimeSlotChange 重新读取时段变更后的项目，与变更前对比写入变更日志并递增版本号，
+// 使持有旧版本的整体更新失效
+func (h *Handler) saveTimeSlotChange(c *gin.Context, tx *sql.Tx, before *models.Project) (models.Project, error) {
+	after, err := scanProject(tx.QueryRow("SELECT "+projectColumns+" FROM projects WHERE id = $1", before.ID))
+	if err != nil {
+		return after, err
+	}
+	h.initializeEmptyTimeSlots(&after)
+	if err := h.loadTimeSlots(tx, &after); err != nil {
+		return after, fmt.Errorf("failed to load time slots: %w", err)
+	}
+
+	userID, _, _, _ := middleware.GetCurrentUser(c)
+	if err := h.recordProjectChanges(before, &after, userID, time.Now()); err != nil {
+		return after, fmt.Errorf("failed to load users: %w", err)
+	}
+	saved, err := saveProject(tx, &after, before.Version)
+	if err != nil {
+		return after, err
+	}
+	if !saved {
+		return after, fmt.Errorf("project %s was modified concurrently", before.ID)
+	}
+	return after, nil
+}
+
+// loadTimeSlot 读取成员的单个时段
+func loadTimeSlot(tx *sql.Tx, target timeSlotTarget, slotID string) (models.TimeSlot, error) {
+	return scanTimeSlot(tx.QueryRow(`
+		SELECT `+timeSlotColumns+`
+		FROM time_slots
+		WHERE id = $1 AND project_id = $2 AND role_key = $3 AND user_id = $4`,
+		slotID, target.ProjectID, target.RoleKey, target.UserID))
+}
+
+// loadMemberTimeSlots 读取成员在该角色下的其他时段，用于重叠检查
+func loadMemberTimeSlots(tx *sql.Tx, target timeSlotTarget, excludeSlotID string) ([]models.TimeSlot, error) {
+	rows, err := tx.Query(`
+		SELECT `+timeSlotColumns+`
+		FROM time_slots
+		WHERE project_id = $1 AND role_key = $2 AND user_id = $3 AND id <> $4`,
+		target.ProjectID, target.RoleKey, target.UserID, excludeSlotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slots []models.TimeSlot
+	for rows.Next() {
+		slot, err := scanTimeSlot(rows)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, slot)
+	}
+	return slots, rows.Err()
+}
+
+// checkMemberTimeSlot 校验时段与成员其他时段不重叠，并返回超出项目日期范围的警告
+// 校验失败或严格模式下存在警告时已写入响应，返回 false
+func checkMemberTimeSlot(c *gin.Context, tx *sql.Tx, project *models.Project, target timeSlotTarget, slot models.TimeSlot) ([]models.TimeSlotWarning, bool) {
+	siblings, err := loadMemberTimeSlots(tx, target, slot.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load time slots: " + err.Error()})
+		return nil, false
+	}
+	if err := checkSlotOverlap(append(siblings, slot)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	warnings := slotWindowWarnings(project, target.RoleKey, target.UserID, slot)
+	if len(warnings) > 0 && strictSlotValidation(c) {
+		respondSlotWarningsRejected(c, warnings)
+		return nil, false
+	}
+	return warnings, true
+}
+
+// GetMemberTimeSlots 获取项目中某角色成员的时段
+func (h *Handler) GetMemberTimeSlots(c *gin.Context) {
+	target := newTimeSlotTarget(c)
+	if !h.checkTimeSlotRole(c, target.RoleKey) {
+		return
+	}
+
+	project, err := h.getProjectByID(target.ProjectID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	member := target.findMember(&project)
+	if member == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found in role"})
+		return
+	}
+
+	c.Header("ETag", projectETag(project.Version))
+	c.JSON(http.StatusOK, member.TimeSlots)
+}
+
+// CreateMemberTimeSlot 为项目中某角色成员新增一个时段
+func (h *Handler) CreateMemberTimeSlot(c *gin.Context) {
+	target := newTimeSlotTarget(c)
+	patch, ok := bindMergePatch(c, "Time slot")
+	if !ok {
+		return
+	}
+
+	slot := models.TimeSlot{ID: newID("slot")}
+	if fieldErrors := applyPatch(&slot, timeSlotPatchers, patch); len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time slot", "fields": fieldErrors})
+		return
+	}
+	if err := normalizeTimeSlot(&slot); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	project, ok := h.lockTimeSlotMember(c, tx, target)
+	if !ok {
+		return
+	}
+	warnings, ok := checkMemberTimeSlot(c, tx, &project, target, slot)
+	if !ok {
+		return
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO time_slots (id, project_id, user_id, role_key, start_date, end_date, description, allocation_percent, hours_per_week)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		slot.ID, target.ProjectID, target.UserID, target.RoleKey,
+		nullableDate(slot.StartDate), nullableDate(slot.EndDate), slot.Description,
+		slot.AllocationPercent, slot.HoursPerWeek)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save time slot: " + err.Error()})
+		return
+	}
+
+	project, err = h.saveTimeSlotChange(c, tx, &project)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.Header("ETag", projectETag(project.Version))
+	c.JSON(http.StatusCreated, timeSlotResponse{TimeSlot: slot, Warnings: warnings})
+}
+
+// UpdateMemberTimeSlot 更新单个时段（JSON Merge Patch），只修改该时段所在行
+func (h *Handler) UpdateMemberTimeSlot(c *gin.Context) {
+	target := newTimeSlotTarget(c)
+	slotID := c.Param("slotId")
+	patch, ok := bindMergePatch(c, "Time slot")
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	project, ok := h.lockTimeSlotMember(c, tx, target)
+	if !ok {
+		return
+	}
+
+	slot, err := loadTimeSlot(tx, target, slotID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Time slot not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if fieldErrors := applyPatch(&slot, timeSlotPatchers, patch); len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time slot", "fields": fieldErrors})
+		return
+	}
+	if err := normalizeTimeSlot(&slot); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	warnings, ok := checkMemberTimeSlot(c, tx, &project, target, slot)
+	if !ok {
+		return
+	}
+
+	_, err = tx.Exec(`
+		UPDATE time_slots
+		SET start_date = $1, end_date = $2, description = $3,
+			allocation_percent = $4, hours_per_week = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6`,
+		nullableDate(slot.StartDate), nullableDate(slot.EndDate), slot.Description,
+		slot.AllocationPercent, slot.HoursPerWeek, slotID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save time slot: " + err.Error()})
+		return
+	}
+
+	project, err = h.saveTimeSlotChange(c, tx, &project)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.Header("ETag", projectETag(project.Version))
+	c.JSON(http.StatusOK, timeSlotResponse{TimeSlot: slot, Warnings: warnings})
+}
+
+// DeleteMemberTimeSlot 删除单个时段
+func (h *Handler) DeleteMemberTimeSlot(c *gin.Context) {
+	target := newTimeSlotTarget(c)
+	slotID := c.Param("slotId")
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	project, ok := h.lockTimeSlotMember(c, tx, target)
+	if !ok {
+		return
+	}
+
+	result, err := tx.Exec(
+		"DELETE FROM time_slots WHERE id = $1 AND project_id = $2 AND role_key = $3 AND user_id = $4",
+		slotID, target.ProjectID, target.RoleKey, target.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Time slot not found"})
+		return
+	}
+
+	project, err = h.saveTimeSlotChange(c, tx, &project)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.Header("ETag", projectETag(project.Version))
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewIDUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id := newID("slot")
+		if !strings.HasPrefix(id, "slot_") {
+			t.Fatalf("id %q missing prefix", id)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %s", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestApplyTimeSlotPatch(t *testing.T) {
+	s := func(v string) *string { return &v }
+	tests := []struct {
+		name       string
+		patch      string
+		want       models.TimeSlot
+		wantErrors []string
+	}{
+		{name: "set dates", patch: `{"startDate":"2026-10-01T00:00:00Z","endDate":"2026-10-31"}`,
+			want: models.TimeSlot{ID: "s1", StartDate: "2026-10-01", EndDate: "2026-10-31", Description: s("旧")}},
+		{name: "null clears fields", patch: `{"endDate":null,"description":null}`,
+			want: models.TimeSlot{ID: "s1", StartDate: "2026-09-01"}},
+		{name: "unknown and read-only fields ignored", patch: `{"id":"other","foo":1}`,
+			want: models.TimeSlot{ID: "s1", StartDate: "2026-09-01", EndDate: "2026-09-30", Description: s("旧")}},
+		{name: "invalid values", patch: `{"startDate":"2026-13-01","description":3}`, wantErrors: []string{"startDate", "description"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatal(err)
+			}
+			slot := models.TimeSlot{ID: "s1", StartDate: "2026-09-01", EndDate: "2026-09-30", Description: s("旧")}
+			fieldErrors := applyPatch(&slot, timeSlotPatchers, patch)
+			if len(tt.wantErrors) > 0 {
+				for _, field := range tt.wantErrors {
+					if _, ok := fieldErrors[field]; !ok {
+						t.Errorf("missing error for %s: %v", field, fieldErrors)
+					}
+				}
+				return
+			}
+			if len(fieldErrors) > 0 {
+				t.Fatalf("unexpected errors %v", fieldErrors)
+			}
+			if slot.ID != tt.want.ID || slot.StartDate != tt.want.StartDate || slot.EndDate != tt.want.EndDate ||
+				derefString(slot.Description) != derefString(tt.want.Description) || (slot.Description == nil) != (tt.want.Description == nil) {
+				t.Errorf("slot = %+v, want %+v", slot, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindMergePatch(t *testing.T) {
+	for body, wantOK := range map[string]bool{`{"a":1}`: true, `null`: false, `[1]`: false, `{`: false} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PATCH", "/", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		_, ok := bindMergePatch(c, "Time slot")
+		if ok != wantOK {
+			t.Errorf("body %s: ok = %v, want %v", body, ok, wantOK)
+		}
+		if !ok && w.Code != http.StatusBadRequest {
+			t.Errorf("body %s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
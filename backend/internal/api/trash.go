@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTrashProjects 获取回收站中的项目，按删除时间倒序
+func (h *Handler) GetTrashProjects(c *gin.Context) {
+	rows, err := h.db.Query("SELECT " + projectColumns + " FROM projects WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	var projectIDs []string
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		h.initializeEmptyTimeSlots(&p)
+		projects = append(projects, p)
+		projectIDs = append(projectIDs, p.ID)
+	}
+
+	if err := h.loadAllTimeSlots(projects, projectIDs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load time slots: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, projects)
+}
+
+// RestoreProject 从回收站恢复项目
+func (h *Handler) RestoreProject(c *gin.Context) {
+	projectID := c.Param("projectId")
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	// 与新增依赖关系串行化，避免恢复的同时新增的关系与该项目的依赖共同成环
+	if _, err := tx.Exec("LOCK TABLE project_dependencies IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := tx.Exec(`
+		UPDATE projects
+		SET deleted_at = NULL, deleted_by = NULL, version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL`, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found in trash"})
+		return
+	}
+
+	// 回收站中的项目不参与成环检查，恢复前需确认其依赖关系不会形成环
+	cycle, err := findProjectDependencyCycle(tx, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if cycle != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Restoring the project would create a dependency cycle: " + strings.Join(cycle, " -> "),
+			"code":  "DEPENDENCY_CYCLE",
+			"cycle": cycle,
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	project, err := h.getProjectByID(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("ETag", projectETag(project.Version))
+	c.JSON(http.StatusOK, project)
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"project-management-backend/internal/middleware"
+	"project-management-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// workflowID 当前只维护一套全局状态流转定义
+const workflowID = "default"
+
+// defaultStatusWorkflow 未配置时使用的默认状态流转：
+// 主流程中的状态可前进或后退一步，任意状态可暂停，暂停后可恢复到任意状态；
+// "项目进行中"用于非研发类项目，可从"未开始"进入并直接完成
+func defaultStatusWorkflow() models.StatusWorkflow {
+	mainFlow := []string{
+		"未开始", "讨论中", "产品设计", "需求完成", "评审完成", "开发中",
+		"开发完成", "测试中", "测试完成", "本周已上线", "已完成",
+	}
+	const paused, generic = "暂停", "项目进行中"
+
+	transitions := make(map[string][]string)
+	for i, status := range mainFlow {
+		if i > 0 {
+			transitions[status] = append(transitions[status], mainFlow[i-1])
+		}
+		if i < len(mainFlow)-1 {
+			transitions[status] = append(transitions[status], mainFlow[i+1])
+		}
+		transitions[status] = append(transitions[status], paused)
+	}
+	transitions["未开始"] = append(transitions["未开始"], generic)
+	transitions[generic] = []string{"未开始", "已完成", paused}
+	for _, status := range models.ProjectStatuses {
+		if status != paused {
+			transitions[paused] = append(transitions[paused], status)
+		}
+	}
+
+	workflow := models.StatusWorkflow{InitialStatus: "未开始"}
+	for i, status := range models.ProjectStatuses {
+		workflow.Statuses = append(workflow.Statuses, models.WorkflowStatus{
+			Name:        status,
+			Order:       i + 1,
+			Transitions: transitions[status],
+		})
+	}
+	return workflow
+}
+
+// findWorkflowStatus 查找状态定义，未定义返回 nil
+func findWorkflowStatus(workflow *models.StatusWorkflow, name string) *models.WorkflowStatus {
+	for i := range workflow.Statuses {
+		if workflow.Statuses[i].Name == name {
+			return &workflow.Statuses[i]
+		}
+	}
+	return nil
+}
+
+// allowedNextStatuses 返回从当前状态可流转到的状态
+// 当前状态不在定义中时（如历史数据），允许流转到任意已定义状态
+func allowedNextStatuses(workflow *models.StatusWorkflow, current string) []string {
+	if status := findWorkflowStatus(workflow, current); status != nil {
+		return status.Transitions
+	}
+	return workflowStatusNames(workflow)
+}
+
+// workflowStatusNames 返回所有已定义状态
+func workflowStatusNames(workflow *models.StatusWorkflow) []string {
+	names := make([]string, 0, len(workflow.Statuses))
+	for _, status := range workflow.Statuses {
+		names = append(names, status.Name)
+	}
+	return names
+}
+
+// statusTransitionError 非法状态流转错误，附带合法的下一状态
+type statusTransitionError struct {
+	From    string
+	To      string
+	Allowed []string
+}
+
+func (e *statusTransitionError) Error() string {
+	if e.From == "" {
+		return fmt.Sprintf("status %q is not defined in the workflow, allowed: %s", e.To, strings.Join(e.Allowed, ", "))
+	}
+	return fmt.Sprintf("cannot change status from %q to %q, allowed: %s", e.From, e.To, strings.Join(e.Allowed, ", "))
+}
+
+// checkStatusTransition 校验状态流转是否合法，from 为空表示新建项目
+func checkStatusTransition(workflow *models.StatusWorkflow, from, to string) *statusTransitionError {
+	if from == "" {
+		if findWorkflowStatus(workflow, to) != nil {
+			return nil
+		}
+		return &statusTransitionError{To: to, Allowed: workflowStatusNames(workflow)}
+	}
+
+	if from == to {
+		return nil
+	}
+	allowed := allowedNextStatuses(workflow, from)
+	for _, status := range allowed {
+		if status == to {
+			return nil
+		}
+	}
+	return &statusTransitionError{From: from, To: to, Allowed: allowed}
+}
+
+// respondStatusTransitionError 返回非法状态流转的统一响应
+func respondStatusTransitionError(c *gin.Context, err *statusTransitionError) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":           err.Error(),
+		"code":            "INVALID_STATUS_TRANSITION",
+		"currentStatus":   err.From,
+		"requestedStatus": err.To,
+		"allowedStatuses": err.Allowed,
+	})
+}
+
+// validateWorkflow 校验状态流转定义
+func validateWorkflow(workflow *models.StatusWorkflow) error {
+	if len(workflow.Statuses) == 0 {
+		return fmt.Errorf("statuses cannot be empty")
+	}
+
+	known := make(map[string]bool)
+	for _, status := range models.ProjectStatuses {
+		known[status] = true
+	}
+
+	defined := make(map[string]bool)
+	for _, status := range workflow.Statuses {
+		if !known[status.Name] {
+			return fmt.Errorf("unknown status: %s", status.Name)
+		}
+		if defined[status.Name] {
+			return fmt.Errorf("duplicate status: %s", status.Name)
+		}
+		defined[status.Name] = true
+	}
+
+	for _, status := range workflow.Statuses {
+		for _, next := range status.Transitions {
+			if !defined[next] {
+				return fmt.Errorf("status %s has transition to undefined status %s", status.Name, next)
+			}
+		}
+	}
+
+	if !defined[workflow.InitialStatus] {
+		return fmt.Errorf("initialStatus %q is not defined", workflow.InitialStatus)
+	}
+	return nil
+}
+
+// loadWorkflow 读取状态流转定义，未配置时返回默认定义
+func (h *Handler) loadWorkflow() (*models.StatusWorkflow, error) {
+	var definition []byte
+	var updatedAt, updatedBy *string
+	err := h.db.QueryRow("SELECT definition, updated_at, updated_by FROM status_workflow WHERE id = $1", workflowID).
+		Scan(&definition, &updatedAt, &updatedBy)
+	if err == sql.ErrNoRows {
+		workflow := defaultStatusWorkflow()
+		return &workflow, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var workflow models.StatusWorkflow
+	if err := json.Unmarshal(definition, &workflow); err != nil {
+		return nil, fmt.Errorf("invalid workflow definition: %w", err)
+	}
+	workflow.UpdatedAt = updatedAt
+	workflow.UpdatedBy = updatedBy
+	return &workflow, nil
+}
+
+// GetStatusWorkflow 获取项目状态流转定义
+func (h *Handler) GetStatusWorkflow(c *gin.Context) {
+	workflow, err := h.loadWorkflow()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, workflow)
+}
+
+// UpdateStatusWorkflow 更新项目状态流转定义（管理员）
+func (h *Handler) UpdateStatusWorkflow(c *gin.Context) {
+	var workflow models.StatusWorkflow
+	if err := c.ShouldBindJSON(&workflow); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateWorkflow(&workflow); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sort.SliceStable(workflow.Statuses, func(i, j int) bool {
+		return workflow.Statuses[i].Order < workflow.Statuses[j].Order
+	})
+	for i := range workflow.Statuses {
+		if workflow.Statuses[i].Transitions == nil {
+			workflow.Statuses[i].Transitions = []string{}
+		}
+	}
+
+	userID, _, _, _ := middleware.GetCurrentUser(c)
+	workflow.UpdatedAt = nil
+	workflow.UpdatedBy = nil
+	definition, _ := json.Marshal(workflow)
+
+	_, err := h.db.Exec(`
+		INSERT INTO status_workflow (id, definition, updated_at, updated_by)
+		VALUES ($1, $2, CURRENT_TIMESTAMP, $3)
+		ON CONFLICT (id)
+		DO UPDATE SET
+			definition = EXCLUDED.definition,
+			updated_at = EXCLUDED.updated_at,
+			updated_by = EXCLUDED.updated_by`,
+		workflowID, definition, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.loadWorkflow()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
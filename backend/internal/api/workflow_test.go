@@ -0,0 +1,85 @@
+package api
+
+import (
+	"testing"
+
+	"project-management-backend/internal/models"
+)
+
+func TestDefaultStatusWorkflowIsValid(t *testing.T) {
+	workflow := defaultStatusWorkflow()
+	if err := validateWorkflow(&workflow); err != nil {
+		t.Fatalf("default workflow is invalid: %v", err)
+	}
+	if len(workflow.Statuses) != len(models.ProjectStatuses) {
+		t.Errorf("default workflow defines %d statuses, want %d", len(workflow.Statuses), len(models.ProjectStatuses))
+	}
+}
+
+func TestCheckStatusTransition(t *testing.T) {
+	workflow := defaultStatusWorkflow()
+	tests := []struct {
+		from, to string
+		wantErr  bool
+	}{
+		{"", "未开始", false},
+		{"", "不存在", true},
+		{"开发中", "开发中", false},
+		{"开发中", "开发完成", false},
+		{"开发中", "评审完成", false},
+		{"开发中", "已完成", true},
+		{"开发中", "暂停", false},
+		{"暂停", "测试中", false},
+		{"未开始", "项目进行中", false},
+		{"项目进行中", "已完成", false},
+		{"项目进行中", "开发中", true},
+		{"历史状态", "开发中", false},
+		{"历史状态", "不存在", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			err := checkStatusTransition(&workflow, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkStatusTransition(%q, %q) = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+			}
+			if err != nil && len(err.Allowed) == 0 {
+				t.Error("error does not list allowed statuses")
+			}
+		})
+	}
+}
+
+func TestValidateWorkflow(t *testing.T) {
+	status := func(name string, transitions ...string) models.WorkflowStatus {
+		return models.WorkflowStatus{Name: name, Transitions: transitions}
+	}
+	tests := []struct {
+		name     string
+		workflow models.StatusWorkflow
+		wantErr  bool
+	}{
+		{"valid", models.StatusWorkflow{InitialStatus: "未开始", Statuses: []models.WorkflowStatus{
+			status("未开始", "开发中"), status("开发中", "未开始"),
+		}}, false},
+		{"empty", models.StatusWorkflow{InitialStatus: "未开始"}, true},
+		{"unknown status", models.StatusWorkflow{InitialStatus: "未开始", Statuses: []models.WorkflowStatus{
+			status("未开始"), status("归档"),
+		}}, true},
+		{"duplicate status", models.StatusWorkflow{InitialStatus: "未开始", Statuses: []models.WorkflowStatus{
+			status("未开始"), status("未开始"),
+		}}, true},
+		{"transition to undefined status", models.StatusWorkflow{InitialStatus: "未开始", Statuses: []models.WorkflowStatus{
+			status("未开始", "开发中"),
+		}}, true},
+		{"undefined initial status", models.StatusWorkflow{InitialStatus: "开发中", Statuses: []models.WorkflowStatus{
+			status("未开始"),
+		}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateWorkflow(&tt.workflow); (err != nil) != tt.wantErr {
+				t.Errorf("validateWorkflow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,11 +2,36 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 )
 
 type Config struct {
-	DatabaseURL string
-	Port        string
+	DatabaseURL        string
+	Port               string
+	TrashRetentionDays int // 回收站项目保留天数，超过后由定时任务永久删除
+
+	// 自动归档：处于完成状态超过指定周数的项目由定时任务归档，0 表示关闭
+	AutoArchiveAfterWeeks int
+	AutoArchiveStatuses   []string
+
+	AdminUserIDs []string // 管理员用户ID，可维护状态流转等全局配置；为空时管理接口一律拒绝
+
+	// 附件：存储后端（目前支持 local）、本地存储目录、单个文件大小上限与允许的 MIME 类型（* 表示不限制）
+	AttachmentStorage      string
+	AttachmentDir          string
+	AttachmentMaxSizeMB    int
+	AttachmentAllowedTypes []string
+
+	Holidays []string // 节假日（YYYY-MM-DD），统计已排期人天时与周末一同排除
+}
+
+// defaultAttachmentTypes 默认允许上传的附件类型（按内容识别），不包含 HTML、SVG 等可在浏览器中执行脚本的类型，
+// 也不包含无法识别内容的 application/octet-stream。新版 Office 文档识别为 application/zip，
+// 旧版 Office 等二进制文件需在 ATTACHMENT_ALLOWED_TYPES 中显式加入 application/octet-stream
+var defaultAttachmentTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp", "image/bmp",
+	"application/pdf", "text/plain", "application/zip", "application/x-gzip",
 }
 
 func Load() *Config {
@@ -19,8 +44,21 @@ func Load() *Config {
 	}
 
 	return &Config{
-		DatabaseURL: databaseURL,
-		Port:        getEnv("PORT", "9000"),
+		DatabaseURL:        databaseURL,
+		Port:               getEnv("PORT", "9000"),
+		TrashRetentionDays: getEnvInt("TRASH_RETENTION_DAYS", 30),
+
+		AutoArchiveAfterWeeks: getEnvInt("AUTO_ARCHIVE_AFTER_WEEKS", 0),
+		AutoArchiveStatuses:   getEnvList("AUTO_ARCHIVE_STATUSES", []string{"已完成"}),
+
+		AdminUserIDs: getEnvList("ADMIN_USER_IDS", nil),
+
+		AttachmentStorage:      getEnv("ATTACHMENT_STORAGE", "local"),
+		AttachmentDir:          getEnv("ATTACHMENT_DIR", "uploads"),
+		AttachmentMaxSizeMB:    getEnvInt("ATTACHMENT_MAX_SIZE_MB", 20),
+		AttachmentAllowedTypes: getEnvList("ATTACHMENT_ALLOWED_TYPES", defaultAttachmentTypes),
+
+		Holidays: getEnvList("HOLIDAYS", nil),
 	}
 }
 
@@ -30,3 +68,27 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList 读取逗号分隔的环境变量
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
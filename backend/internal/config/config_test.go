@@ -0,0 +1,39 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetEnvList(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", []string{"default"}},
+		{"已完成", []string{"已完成"}},
+		{" 已完成 , 已上线,,", []string{"已完成", "已上线"}},
+	}
+	for _, tt := range tests {
+		t.Setenv("TEST_ENV_LIST", tt.value)
+		if got := getEnvList("TEST_ENV_LIST", []string{"default"}); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("getEnvList(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultAttachmentTypes(t *testing.T) {
+	t.Setenv("ATTACHMENT_ALLOWED_TYPES", "")
+	cfg := Load()
+	for _, mimeType := range cfg.AttachmentAllowedTypes {
+		switch mimeType {
+		case "*", "text/html", "image/svg+xml", "application/octet-stream":
+			t.Errorf("default attachment types include %q", mimeType)
+		}
+	}
+
+	t.Setenv("ATTACHMENT_ALLOWED_TYPES", "image/*, application/octet-stream")
+	if got, want := Load().AttachmentAllowedTypes, []string{"image/*", "application/octet-stream"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AttachmentAllowedTypes = %v, want %v", got, want)
+	}
+}
@@ -53,7 +53,7 @@ func createTables(db *sql.DB) error {
 		}
 	}
 
-	var usersTable, okrSetsTable, projectsTable string
+	var usersTable, okrSetsTable, projectsTable, statusWorkflowTable, timeSlotsTable, projectRolesTable, projectDependenciesTable, projectMilestonesTable, projectTasksTable, labelsTable, projectLabelsTable, customFieldsTable, attachmentsTable, projectLinksTable, projectRisksTable, healthHistoryTable, effortEstimatesTable string
 
 	if isPostgreSQL {
 		// PostgreSQL 版本
@@ -93,7 +93,171 @@ func createTables(db *sql.DB) error {
 			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
 			followers TEXT[],
 			comments JSONB,
-			change_log JSONB
+			change_log JSONB,
+			version INTEGER NOT NULL DEFAULT 1,
+			deleted_at TIMESTAMP WITH TIME ZONE NULL,
+			deleted_by VARCHAR(255) NULL,
+			archived_at TIMESTAMP WITH TIME ZONE NULL,
+			archived_by VARCHAR(255) NULL,
+			status_changed_at TIMESTAMP WITH TIME ZONE NULL,
+			members JSONB NOT NULL DEFAULT '{}',
+			custom_fields JSONB NOT NULL DEFAULT '{}',
+			health VARCHAR(10),
+			health_reason TEXT,
+			last_week_health VARCHAR(10)
+		);`
+
+		projectRolesTable = `
+		CREATE TABLE IF NOT EXISTS project_roles (
+			role_key VARCHAR(50) PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			sort_order INTEGER NOT NULL DEFAULT 0,
+			built_in BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		statusWorkflowTable = `
+		CREATE TABLE IF NOT EXISTS status_workflow (
+			id VARCHAR(50) PRIMARY KEY,
+			definition JSONB NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_by VARCHAR(255)
+		);`
+
+		timeSlotsTable = `
+		CREATE TABLE IF NOT EXISTS time_slots (
+			id VARCHAR(50) PRIMARY KEY,
+			project_id VARCHAR(50) NOT NULL,
+			user_id VARCHAR(50) NOT NULL,
+			role_key VARCHAR(50) NOT NULL,
+			start_date DATE,
+			end_date DATE,
+			description TEXT,
+			allocation_percent INTEGER NOT NULL DEFAULT 100,
+			hours_per_week NUMERIC(5,2),
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		projectDependenciesTable = `
+		CREATE TABLE IF NOT EXISTS project_dependencies (
+			id VARCHAR(50) PRIMARY KEY,
+			blocker_id VARCHAR(255) NOT NULL,
+			blocked_id VARCHAR(255) NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			created_by VARCHAR(255),
+			UNIQUE (blocker_id, blocked_id)
+		);`
+
+		projectMilestonesTable = `
+		CREATE TABLE IF NOT EXISTS project_milestones (
+			id VARCHAR(50) PRIMARY KEY,
+			project_id VARCHAR(255) NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			planned_date DATE NOT NULL,
+			actual_date DATE,
+			owner_id VARCHAR(255),
+			done BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		projectTasksTable = `
+		CREATE TABLE IF NOT EXISTS project_tasks (
+			id VARCHAR(50) PRIMARY KEY,
+			project_id VARCHAR(255) NOT NULL,
+			parent_id VARCHAR(50),
+			title TEXT NOT NULL,
+			assignee_id VARCHAR(255),
+			status VARCHAR(50) NOT NULL,
+			due_date DATE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		labelsTable = `
+		CREATE TABLE IF NOT EXISTS labels (
+			id VARCHAR(50) PRIMARY KEY,
+			name VARCHAR(255) NOT NULL UNIQUE,
+			color VARCHAR(7) NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		projectLabelsTable = `
+		CREATE TABLE IF NOT EXISTS project_labels (
+			project_id VARCHAR(255) NOT NULL,
+			label_id VARCHAR(50) NOT NULL,
+			PRIMARY KEY (project_id, label_id)
+		);`
+
+		customFieldsTable = `
+		CREATE TABLE IF NOT EXISTS custom_fields (
+			field_key VARCHAR(50) PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			field_type VARCHAR(20) NOT NULL,
+			options JSONB NOT NULL DEFAULT '[]',
+			sort_order INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		attachmentsTable = `
+		CREATE TABLE IF NOT EXISTS project_attachments (
+			id VARCHAR(50) PRIMARY KEY,
+			project_id VARCHAR(255) NOT NULL,
+			comment_id VARCHAR(255),
+			file_name TEXT NOT NULL,
+			size BIGINT NOT NULL,
+			mime_type VARCHAR(255) NOT NULL,
+			checksum VARCHAR(64) NOT NULL,
+			uploaded_by VARCHAR(255),
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		projectLinksTable = `
+		CREATE TABLE IF NOT EXISTS project_links (
+			id VARCHAR(50) PRIMARY KEY,
+			project_id VARCHAR(255) NOT NULL,
+			kind VARCHAR(20) NOT NULL,
+			title TEXT NOT NULL DEFAULT '',
+			url TEXT NOT NULL,
+			created_by VARCHAR(255),
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (project_id, url)
+		);`
+
+		projectRisksTable = `
+		CREATE TABLE IF NOT EXISTS project_risks (
+			id VARCHAR(50) PRIMARY KEY,
+			project_id VARCHAR(255) NOT NULL,
+			description TEXT NOT NULL,
+			probability VARCHAR(10) NOT NULL,
+			impact VARCHAR(10) NOT NULL,
+			owner_id VARCHAR(255),
+			mitigation TEXT,
+			status VARCHAR(20) NOT NULL,
+			due_date DATE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		healthHistoryTable = `
+		CREATE TABLE IF NOT EXISTS project_health_history (
+			id VARCHAR(50) PRIMARY KEY,
+			project_id VARCHAR(255) NOT NULL,
+			health VARCHAR(10),
+			reason TEXT,
+			changed_by VARCHAR(255),
+			changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		effortEstimatesTable = `
+		CREATE TABLE IF NOT EXISTS project_effort_estimates (
+			project_id VARCHAR(255) NOT NULL,
+			role_key VARCHAR(50) NOT NULL,
+			person_days NUMERIC(10,2) NOT NULL,
+			updated_by VARCHAR(255),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (project_id, role_key)
 		);`
 	} else {
 		// SQLite 版本
@@ -133,11 +297,175 @@ func createTables(db *sql.DB) error {
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			followers TEXT,
 			comments TEXT,
-			change_log TEXT
+			change_log TEXT,
+			version INTEGER NOT NULL DEFAULT 1,
+			deleted_at DATETIME,
+			deleted_by TEXT,
+			archived_at DATETIME,
+			archived_by TEXT,
+			status_changed_at DATETIME,
+			members TEXT NOT NULL DEFAULT '{}',
+			custom_fields TEXT NOT NULL DEFAULT '{}',
+			health TEXT,
+			health_reason TEXT,
+			last_week_health TEXT
+		);`
+
+		projectRolesTable = `
+		CREATE TABLE IF NOT EXISTS project_roles (
+			role_key TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			sort_order INTEGER NOT NULL DEFAULT 0,
+			built_in BOOLEAN NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		statusWorkflowTable = `
+		CREATE TABLE IF NOT EXISTS status_workflow (
+			id TEXT PRIMARY KEY,
+			definition TEXT NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		);`
+
+		timeSlotsTable = `
+		CREATE TABLE IF NOT EXISTS time_slots (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			role_key TEXT NOT NULL,
+			start_date DATE,
+			end_date DATE,
+			description TEXT,
+			allocation_percent INTEGER NOT NULL DEFAULT 100,
+			hours_per_week REAL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		projectDependenciesTable = `
+		CREATE TABLE IF NOT EXISTS project_dependencies (
+			id TEXT PRIMARY KEY,
+			blocker_id TEXT NOT NULL,
+			blocked_id TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			UNIQUE (blocker_id, blocked_id)
+		);`
+
+		projectMilestonesTable = `
+		CREATE TABLE IF NOT EXISTS project_milestones (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			planned_date DATE NOT NULL,
+			actual_date DATE,
+			owner_id TEXT,
+			done BOOLEAN NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		projectTasksTable = `
+		CREATE TABLE IF NOT EXISTS project_tasks (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			parent_id TEXT,
+			title TEXT NOT NULL,
+			assignee_id TEXT,
+			status TEXT NOT NULL,
+			due_date DATE,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		labelsTable = `
+		CREATE TABLE IF NOT EXISTS labels (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE,
+			color TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		projectLabelsTable = `
+		CREATE TABLE IF NOT EXISTS project_labels (
+			project_id TEXT NOT NULL,
+			label_id TEXT NOT NULL,
+			PRIMARY KEY (project_id, label_id)
+		);`
+
+		customFieldsTable = `
+		CREATE TABLE IF NOT EXISTS custom_fields (
+			field_key TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			field_type TEXT NOT NULL,
+			options TEXT NOT NULL DEFAULT '[]',
+			sort_order INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		attachmentsTable = `
+		CREATE TABLE IF NOT EXISTS project_attachments (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			comment_id TEXT,
+			file_name TEXT NOT NULL,
+			size INTEGER NOT NULL,
+			mime_type TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			uploaded_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		projectLinksTable = `
+		CREATE TABLE IF NOT EXISTS project_links (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			title TEXT NOT NULL DEFAULT '',
+			url TEXT NOT NULL,
+			created_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (project_id, url)
+		);`
+
+		projectRisksTable = `
+		CREATE TABLE IF NOT EXISTS project_risks (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			description TEXT NOT NULL,
+			probability TEXT NOT NULL,
+			impact TEXT NOT NULL,
+			owner_id TEXT,
+			mitigation TEXT,
+			status TEXT NOT NULL,
+			due_date DATE,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		healthHistoryTable = `
+		CREATE TABLE IF NOT EXISTS project_health_history (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			health TEXT,
+			reason TEXT,
+			changed_by TEXT,
+			changed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`
+
+		effortEstimatesTable = `
+		CREATE TABLE IF NOT EXISTS project_effort_estimates (
+			project_id TEXT NOT NULL,
+			role_key TEXT NOT NULL,
+			person_days REAL NOT NULL,
+			updated_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (project_id, role_key)
 		);`
 	}
 
-	tables := []string{usersTable, okrSetsTable, projectsTable}
+	tables := []string{usersTable, okrSetsTable, projectsTable, statusWorkflowTable, timeSlotsTable, projectRolesTable, projectDependenciesTable, projectMilestonesTable, projectTasksTable, labelsTable, projectLabelsTable, customFieldsTable, attachmentsTable, projectLinksTable, projectRisksTable, healthHistoryTable, effortEstimatesTable}
 
 	for _, table := range tables {
 		if _, err := db.Exec(table); err != nil {
@@ -145,6 +473,44 @@ func createTables(db *sql.DB) error {
 		}
 	}
 
+	// 索引（PostgreSQL 与 SQLite 语法相同）
+	indexes := []string{
+		"CREATE INDEX IF NOT EXISTS idx_time_slots_project_member ON time_slots (project_id, role_key, user_id)",
+		"CREATE INDEX IF NOT EXISTS idx_project_dependencies_blocked ON project_dependencies (blocked_id)",
+		"CREATE INDEX IF NOT EXISTS idx_project_milestones_project ON project_milestones (project_id, planned_date)",
+		"CREATE INDEX IF NOT EXISTS idx_project_tasks_project ON project_tasks (project_id, parent_id)",
+		"CREATE INDEX IF NOT EXISTS idx_project_labels_label ON project_labels (label_id)",
+		"CREATE INDEX IF NOT EXISTS idx_project_attachments_project ON project_attachments (project_id, comment_id)",
+		"CREATE INDEX IF NOT EXISTS idx_project_risks_project ON project_risks (project_id)",
+		"CREATE INDEX IF NOT EXISTS idx_project_risks_status ON project_risks (status)",
+		"CREATE INDEX IF NOT EXISTS idx_project_health_history_project ON project_health_history (project_id, changed_at)",
+	}
+
+	for _, index := range indexes {
+		if _, err := db.Exec(index); err != nil {
+			return fmt.Errorf("failed to create index: %w", err)
+		}
+	}
+
+	// 内置角色（对应 projects 表上的四个角色列）
+	builtInRoles := []struct {
+		key, name string
+	}{
+		{"productManagers", "产品经理"},
+		{"backendDevelopers", "后端研发"},
+		{"frontendDevelopers", "前端研发"},
+		{"qaTesters", "测试"},
+	}
+	for i, role := range builtInRoles {
+		_, err := db.Exec(`
+			INSERT INTO project_roles (role_key, name, sort_order, built_in)
+			VALUES ($1, $2, $3, TRUE)
+			ON CONFLICT (role_key) DO NOTHING`, role.key, role.name, i+1)
+		if err != nil {
+			return fmt.Errorf("failed to seed project roles: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -176,8 +542,99 @@ func runMigrations(db *sql.DB) error {
 		if _, err := db.Exec(addCreatedAtColumn); err != nil {
 			return fmt.Errorf("failed to add created_at column: %w", err)
 		}
+
+		// 乐观锁版本号
+		if err := addColumnIfNotExists(db, "projects", "version", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+			return err
+		}
+
+		// 软删除（回收站）
+		if err := addColumnIfNotExists(db, "projects", "deleted_at", "TIMESTAMP WITH TIME ZONE NULL"); err != nil {
+			return err
+		}
+		if err := addColumnIfNotExists(db, "projects", "deleted_by", "VARCHAR(255) NULL"); err != nil {
+			return err
+		}
+
+		// 归档及状态变更时间（自动归档依据）
+		if err := addColumnIfNotExists(db, "projects", "archived_at", "TIMESTAMP WITH TIME ZONE NULL"); err != nil {
+			return err
+		}
+		if err := addColumnIfNotExists(db, "projects", "archived_by", "VARCHAR(255) NULL"); err != nil {
+			return err
+		}
+		if err := addColumnIfNotExists(db, "projects", "status_changed_at", "TIMESTAMP WITH TIME ZONE NULL"); err != nil {
+			return err
+		}
+
+		// 时段单独维护后需要记录更新时间
+		if err := addColumnIfNotExists(db, "time_slots", "created_at", "TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP"); err != nil {
+			return err
+		}
+		if err := addColumnIfNotExists(db, "time_slots", "updated_at", "TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP"); err != nil {
+			return err
+		}
+
+		// 自定义角色成员
+		if err := addColumnIfNotExists(db, "projects", "members", "JSONB NOT NULL DEFAULT '{}'"); err != nil {
+			return err
+		}
+
+		// 时段中出现但未登记的历史角色键登记为自定义角色，保证 role_key 均可在角色表中找到
+		registerLegacyRoles := `
+		INSERT INTO project_roles (role_key, name, sort_order)
+		SELECT DISTINCT role_key, role_key, 100 FROM time_slots
+		WHERE role_key NOT IN (SELECT role_key FROM project_roles)
+		ON CONFLICT (role_key) DO NOTHING;`
+
+		if _, err := db.Exec(registerLegacyRoles); err != nil {
+			return fmt.Errorf("failed to register legacy roles: %w", err)
+		}
+
+		// 时段投入比例
+		if err := addColumnIfNotExists(db, "time_slots", "allocation_percent", "INTEGER NOT NULL DEFAULT 100"); err != nil {
+			return err
+		}
+		if err := addColumnIfNotExists(db, "time_slots", "hours_per_week", "NUMERIC(5,2) NULL"); err != nil {
+			return err
+		}
+
+		// 自定义字段的值
+		if err := addColumnIfNotExists(db, "projects", "custom_fields", "JSONB NOT NULL DEFAULT '{}'"); err != nil {
+			return err
+		}
+
+		// 项目健康度
+		if err := addColumnIfNotExists(db, "projects", "health", "VARCHAR(10) NULL"); err != nil {
+			return err
+		}
+		if err := addColumnIfNotExists(db, "projects", "health_reason", "TEXT NULL"); err != nil {
+			return err
+		}
+		if err := addColumnIfNotExists(db, "projects", "last_week_health", "VARCHAR(10) NULL"); err != nil {
+			return err
+		}
 	}
 	// SQLite 不需要特殊的迁移，因为表创建时已经包含了所有字段
 
 	return nil
 }
+
+// addColumnIfNotExists 为 PostgreSQL 表添加缺失的列
+func addColumnIfNotExists(db *sql.DB, table, column, definition string) error {
+	query := fmt.Sprintf(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1 FROM information_schema.columns
+				WHERE table_name = '%s' AND column_name = '%s'
+			) THEN
+				ALTER TABLE %s ADD COLUMN %s %s;
+			END IF;
+		END $$;`, table, column, table, column, definition)
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to add %s.%s column: %w", table, column, err)
+	}
+	return nil
+}
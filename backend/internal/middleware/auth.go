@@ -151,11 +151,35 @@ func GetCurrentUser(c *gin.Context) (userID, email, name string, exists bool) {
 	return userID, email, name, true
 }
 
-// RequireRole 角色权限中间件（预留扩展）
+// roleMembers 角色到用户ID集合的映射，由 SetRoleMembers 在启动时配置
+var roleMembers = map[string]map[string]bool{}
+
+// SetRoleMembers 配置拥有指定角色的用户
+func SetRoleMembers(role string, userIDs []string) {
+	members := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		members[id] = true
+	}
+	roleMembers[role] = members
+}
+
+// RequireRole 角色权限中间件，需在 AuthMiddleware 之后使用
+// 未配置成员的角色任何用户都不满足，即默认拒绝
 func RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 这里可以根据需要实现基于角色的权限控制
-		// 目前先通过，后续可以扩展
-		c.Next()
+		userID, _, _, _ := GetCurrentUser(c)
+		for _, role := range roles {
+			if roleMembers[role][userID] {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "forbidden",
+			"message": "没有执行此操作的权限",
+			"code":    "AUTH_ROLE_REQUIRED",
+		})
+		c.Abort()
 	}
 }
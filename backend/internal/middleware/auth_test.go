@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name    string
+		members []string
+		userID  string
+		want    int
+	}{
+		{"member", []string{"u1", "u2"}, "u2", http.StatusOK},
+		{"not a member", []string{"u1"}, "u2", http.StatusForbidden},
+		{"no members configured", nil, "u1", http.StatusForbidden},
+		{"anonymous", []string{"u1"}, "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetRoleMembers("admin", tt.members)
+			t.Cleanup(func() { SetRoleMembers("admin", nil) })
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.userID != "" {
+					c.Set("user_id", tt.userID)
+					c.Set("user_email", tt.userID+"@example.com")
+					c.Set("user_name", tt.userID)
+				}
+			})
+			router.PUT("/admin", RequireRole("admin"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("PUT", "/admin", nil))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -21,6 +21,18 @@ type TimeSlot struct {
 	StartDate   string  `json:"startDate"`
 	EndDate     string  `json:"endDate"`
 	Description *string `json:"description,omitempty"`
+	// 投入比例（1-100），未设置时按 100% 计算
+	AllocationPercent int      `json:"allocationPercent"`
+	HoursPerWeek      *float64 `json:"hoursPerWeek,omitempty"`
+}
+
+// TimeSlotWarning 时段校验警告（不阻止保存，严格模式下视为错误）
+type TimeSlotWarning struct {
+	Code    string `json:"code"`
+	Role    string `json:"role"`
+	UserID  string `json:"userId"`
+	SlotID  string `json:"slotId"`
+	Message string `json:"message"`
 }
 
 // TeamMember 团队成员
@@ -78,26 +90,263 @@ type OkrSet struct {
 	Okrs       []OKR  `json:"okrs" db:"okrs"`
 }
 
+// ProjectRole 项目角色定义，内置角色对应 Project 上的固定字段
+type ProjectRole struct {
+	Key       string `json:"key" db:"role_key"`
+	Name      string `json:"name" db:"name"`
+	SortOrder int    `json:"sortOrder" db:"sort_order"`
+	BuiltIn   bool   `json:"builtIn" db:"built_in"`
+}
+
 // Project 项目模型
 type Project struct {
-	ID                 string           `json:"id" db:"id"`
-	Name               string           `json:"name" db:"name"`
-	Priority           string           `json:"priority" db:"priority"`
-	BusinessProblem    *string          `json:"businessProblem" db:"business_problem"`
-	KeyResultIds       []string         `json:"keyResultIds" db:"key_result_ids"`
-	WeeklyUpdate       *string          `json:"weeklyUpdate" db:"weekly_update"`
-	LastWeekUpdate     *string          `json:"lastWeekUpdate" db:"last_week_update"`
-	Status             string           `json:"status" db:"status"`
-	ProductManagers    Role             `json:"productManagers" db:"product_managers"`
-	BackendDevelopers  Role             `json:"backendDevelopers" db:"backend_developers"`
-	FrontendDevelopers Role             `json:"frontendDevelopers" db:"frontend_developers"`
-	QaTesters          Role             `json:"qaTesters" db:"qa_testers"`
-	ProposalDate       *string          `json:"proposedDate" db:"proposal_date"`
-	LaunchDate         *string          `json:"launchDate" db:"launch_date"`
-	CreatedAt          string           `json:"createdAt" db:"created_at"`
-	Followers          []string         `json:"followers" db:"followers"`
-	Comments           []Comment        `json:"comments" db:"comments"`
-	ChangeLog          []ChangeLogEntry `json:"changeLog" db:"change_log"`
+	ID                 string                 `json:"id" db:"id"`
+	Name               string                 `json:"name" db:"name"`
+	Priority           string                 `json:"priority" db:"priority"`
+	BusinessProblem    *string                `json:"businessProblem" db:"business_problem"`
+	KeyResultIds       []string               `json:"keyResultIds" db:"key_result_ids"`
+	WeeklyUpdate       *string                `json:"weeklyUpdate" db:"weekly_update"`
+	LastWeekUpdate     *string                `json:"lastWeekUpdate" db:"last_week_update"`
+	Status             string                 `json:"status" db:"status"`
+	Health             *string                `json:"health" db:"health"`                   // 健康度 green / amber / red，未评估时为 null
+	HealthReason       *string                `json:"healthReason" db:"health_reason"`      // 健康度说明，非绿色时必填
+	LastWeekHealth     *string                `json:"lastWeekHealth" db:"last_week_health"` // 上次周会滚动时的健康度，只读
+	ProductManagers    Role                   `json:"productManagers" db:"product_managers"`
+	BackendDevelopers  Role                   `json:"backendDevelopers" db:"backend_developers"`
+	FrontendDevelopers Role                   `json:"frontendDevelopers" db:"frontend_developers"`
+	QaTesters          Role                   `json:"qaTesters" db:"qa_testers"`
+	Members            map[string]Role        `json:"members" db:"members"`            // 自定义角色成员，键为角色键；内置角色仍使用上面的字段
+	CustomFields       map[string]interface{} `json:"customFields" db:"custom_fields"` // 自定义字段的值，键为字段键
+	ProposalDate       *string                `json:"proposedDate" db:"proposal_date"`
+	LaunchDate         *string                `json:"launchDate" db:"launch_date"`
+	CreatedAt          string                 `json:"createdAt" db:"created_at"`
+	Followers          []string               `json:"followers" db:"followers"`
+	Comments           []Comment              `json:"comments" db:"comments"`
+	ChangeLog          []ChangeLogEntry       `json:"changeLog" db:"change_log"`
+	Version            int                    `json:"version" db:"version"` // 乐观锁版本号，每次更新递增
+	DeletedAt          *string                `json:"deletedAt,omitempty" db:"deleted_at"`
+	DeletedBy          *string                `json:"deletedBy,omitempty" db:"deleted_by"`
+	ArchivedAt         *string                `json:"archivedAt,omitempty" db:"archived_at"`
+	ArchivedBy         *string                `json:"archivedBy,omitempty" db:"archived_by"`
+	StatusChangedAt    *string                `json:"statusChangedAt,omitempty" db:"status_changed_at"`
+	MilestoneSummary   *MilestoneSummary      `json:"milestoneSummary,omitempty"`
+	TaskSummary        *TaskSummary           `json:"taskSummary,omitempty"`
+	Labels             []Label                `json:"labels,omitempty"`
+	Links              []ProjectLink          `json:"links,omitempty"`
+	LinkKinds          []string               `json:"linkKinds,omitempty"` // 项目已有的链接类型，用于列表中的徽标
+}
+
+// Milestone 项目里程碑
+type Milestone struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	PlannedDate string  `json:"plannedDate"`
+	ActualDate  *string `json:"actualDate"`
+	OwnerID     *string `json:"ownerId"`
+	Done        bool    `json:"done"`
+	CreatedAt   string  `json:"createdAt"`
+	UpdatedAt   string  `json:"updatedAt"`
+}
+
+// MilestoneSummary 项目里程碑概览：下一个未完成的里程碑及已逾期的里程碑
+type MilestoneSummary struct {
+	Total   int         `json:"total"`
+	Done    int         `json:"done"`
+	Next    *Milestone  `json:"next"`
+	Overdue []Milestone `json:"overdue"`
+}
+
+// Task 项目任务，ParentID 不为空时为子任务
+type Task struct {
+	ID         string  `json:"id"`
+	ParentID   *string `json:"parentId"`
+	Title      string  `json:"title"`
+	AssigneeID *string `json:"assigneeId"`
+	Status     string  `json:"status"`
+	DueDate    *string `json:"dueDate"`
+	CreatedAt  string  `json:"createdAt"`
+	UpdatedAt  string  `json:"updatedAt"`
+}
+
+// TaskStatuses 任务状态
+var TaskStatuses = []string{"未开始", "进行中", "已完成"}
+
+// TaskSummary 项目任务完成情况（只统计没有子任务的任务）
+type TaskSummary struct {
+	Total             int `json:"total"`
+	Done              int `json:"done"`
+	CompletionPercent int `json:"completionPercent"`
+}
+
+// CustomField 管理员定义的项目自定义字段
+type CustomField struct {
+	Key       string   `json:"key"`
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Options   []string `json:"options,omitempty"` // select 类型的可选值
+	SortOrder int      `json:"sortOrder"`
+}
+
+// CustomFieldTypes 自定义字段支持的类型
+var CustomFieldTypes = []string{"text", "number", "date", "select", "user"}
+
+// Attachment 项目附件的元数据，文件内容保存在存储后端
+type Attachment struct {
+	ID         string  `json:"id"`
+	ProjectID  string  `json:"projectId"`
+	CommentID  *string `json:"commentId"`
+	FileName   string  `json:"fileName"`
+	Size       int64   `json:"size"`
+	MimeType   string  `json:"mimeType"` // 根据文件内容识别
+	Checksum   string  `json:"checksum"` // SHA-256，十六进制
+	UploadedBy *string `json:"uploadedBy"`
+	CreatedAt  string  `json:"createdAt"`
+}
+
+// HealthStatuses 项目健康度，按由好到坏排列
+var HealthStatuses = []string{"green", "amber", "red"}
+
+// HealthChange 项目健康度的一次变更记录
+type HealthChange struct {
+	ID        string  `json:"id"`
+	Health    *string `json:"health"`
+	Reason    *string `json:"reason"`
+	ChangedBy *string `json:"changedBy"`
+	ChangedAt string  `json:"changedAt"`
+}
+
+// HealthWorsening 自上次周会滚动以来健康度变差的项目
+type HealthWorsening struct {
+	ProjectID       string  `json:"projectId"`
+	ProjectName     string  `json:"projectName"`
+	Status          string  `json:"status"`
+	PreviousHealth  *string `json:"previousHealth"`
+	Health          string  `json:"health"`
+	HealthReason    *string `json:"healthReason"`
+	HealthChangedAt *string `json:"healthChangedAt"`
+}
+
+// EffortEstimate 项目某角色的预估工作量（人天）
+type EffortEstimate struct {
+	RoleKey    string  `json:"roleKey"`
+	PersonDays float64 `json:"personDays"`
+	UpdatedBy  *string `json:"updatedBy"`
+	UpdatedAt  string  `json:"updatedAt"`
+}
+
+// EffortMember 成员在某角色上已排期的工作量
+type EffortMember struct {
+	UserID           string  `json:"userId"`
+	Name             string  `json:"name"`
+	BookedDays       int     `json:"bookedDays"`       // 有排期的工作日数
+	BookedPersonDays float64 `json:"bookedPersonDays"` // 按投入比例折算的人天
+}
+
+// EffortRole 项目某角色的预估与实际排期对比
+type EffortRole struct {
+	RoleKey             string   `json:"roleKey"`
+	RoleName            string   `json:"roleName"`
+	EstimatedPersonDays *float64 `json:"estimatedPersonDays"`
+	BookedPersonDays    float64  `json:"bookedPersonDays"`
+	// 偏差 = 已排期 - 预估，正数表示超出预估；未填写预估时为 null，预估为 0 时百分比为 null
+	VariancePersonDays *float64       `json:"variancePersonDays"`
+	VariancePercent    *float64       `json:"variancePercent"`
+	Members            []EffortMember `json:"members"`
+}
+
+// EffortReport 项目工作量预估与时段实际排期的对比
+type EffortReport struct {
+	ProjectID           string       `json:"projectId"`
+	EstimatedPersonDays *float64     `json:"estimatedPersonDays"`
+	BookedPersonDays    float64      `json:"bookedPersonDays"`
+	VariancePersonDays  *float64     `json:"variancePersonDays"`
+	VariancePercent     *float64     `json:"variancePercent"`
+	Roles               []EffortRole `json:"roles"`
+}
+
+// Risk 项目风险与问题登记
+type Risk struct {
+	ID          string  `json:"id"`
+	ProjectID   string  `json:"projectId"`
+	ProjectName string  `json:"projectName,omitempty"` // 仅跨项目查询时返回
+	Description string  `json:"description"`
+	Probability string  `json:"probability"`
+	Impact      string  `json:"impact"`
+	Severity    int     `json:"severity"` // 可能性 × 影响，1-9
+	OwnerID     *string `json:"ownerId"`
+	Mitigation  *string `json:"mitigation"`
+	Status      string  `json:"status"`
+	DueDate     *string `json:"dueDate"`
+	CreatedAt   string  `json:"createdAt"`
+	UpdatedAt   string  `json:"updatedAt"`
+}
+
+// RiskLevels 风险可能性与影响的等级，由低到高依次计 1-3 分
+var RiskLevels = []string{"低", "中", "高"}
+
+// RiskStatuses 风险状态，已发生表示风险已转为问题
+var RiskStatuses = []string{"待处理", "应对中", "已发生", "已关闭"}
+
+// ProjectLink 项目的外部链接（PRD、设计稿、代码仓库、数据看板等）
+type ProjectLink struct {
+	ID        string  `json:"id"`
+	Kind      string  `json:"kind"`
+	Title     string  `json:"title"`
+	URL       string  `json:"url"`
+	CreatedBy *string `json:"createdBy"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+// LinkKinds 支持的链接类型，同时决定 linkKinds 徽标的顺序
+var LinkKinds = []string{"prd", "design", "repository", "dashboard", "other"}
+
+// Label 项目标签（标签目录中的一项）
+type Label struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// ProjectDependency 项目依赖关系，Type 为相对当前项目的方向（blocks / blockedBy）
+type ProjectDependency struct {
+	ID          string  `json:"id"`
+	Type        string  `json:"type"`
+	ProjectID   string  `json:"projectId"`
+	ProjectName string  `json:"projectName"`
+	Status      string  `json:"status"`
+	LaunchDate  *string `json:"launchDate"`
+	CreatedAt   string  `json:"createdAt"`
+	CreatedBy   string  `json:"createdBy"`
+}
+
+// DependencyWarning 依赖关系警告（不阻止保存）
+type DependencyWarning struct {
+	Code      string `json:"code"`
+	BlockerID string `json:"blockerId"`
+	BlockedID string `json:"blockedId"`
+	Message   string `json:"message"`
+}
+
+// ProjectStatuses 系统支持的项目状态（与前端 ProjectStatus 枚举一致）
+var ProjectStatuses = []string{
+	"未开始", "讨论中", "产品设计", "需求完成", "评审完成", "开发中", "开发完成",
+	"测试中", "测试完成", "本周已上线", "已完成", "暂停", "项目进行中",
+}
+
+// WorkflowStatus 状态流转定义中的单个状态
+type WorkflowStatus struct {
+	Name        string   `json:"name"`
+	Order       int      `json:"order"`
+	Transitions []string `json:"transitions"` // 允许流转到的下一状态
+}
+
+// StatusWorkflow 项目状态流转定义
+type StatusWorkflow struct {
+	InitialStatus string           `json:"initialStatus"`
+	Statuses      []WorkflowStatus `json:"statuses"`
+	UpdatedAt     *string          `json:"updatedAt,omitempty"`
+	UpdatedBy     *string          `json:"updatedBy,omitempty"`
 }
 
 // EmployeeResponse 员工接口响应
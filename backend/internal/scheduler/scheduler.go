@@ -8,14 +8,19 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"project-management-backend/internal/config"
 	"project-management-backend/internal/models"
+	"project-management-backend/internal/storage"
 
+	"github.com/lib/pq"
 	"github.com/robfig/cron/v3"
 )
 
-func Start(db *sql.DB) {
+// Start 启动定时任务，files 为附件存储，清理回收站时一并删除附件文件
+func Start(db *sql.DB, cfg *config.Config, files storage.Storage) {
 	c := cron.New()
 
 	// 每天上午11:00执行员工数据同步
@@ -28,10 +33,136 @@ func Start(db *sql.DB) {
 		}
 	})
 
+	// 每天凌晨3:00清理超过保留期的回收站项目
+	if cfg.TrashRetentionDays > 0 {
+		c.AddFunc("0 3 * * *", func() {
+			purged, err := purgeDeletedProjects(db, files, cfg.TrashRetentionDays)
+			if err != nil {
+				log.Printf("Trash purge failed: %v", err)
+			} else {
+				log.Printf("Trash purge completed, %d projects permanently deleted", purged)
+			}
+		})
+	}
+
+	// 每天凌晨3:30自动归档长期处于完成状态的项目
+	if cfg.AutoArchiveAfterWeeks > 0 && len(cfg.AutoArchiveStatuses) > 0 {
+		c.AddFunc("30 3 * * *", func() {
+			archived, err := autoArchiveProjects(db, cfg.AutoArchiveStatuses, cfg.AutoArchiveAfterWeeks)
+			if err != nil {
+				log.Printf("Auto archive failed: %v", err)
+			} else {
+				log.Printf("Auto archive completed, %d projects archived", archived)
+			}
+		})
+	}
+
 	c.Start()
 	log.Println("Scheduler started - employee sync scheduled for 11:00 AM daily")
 }
 
+// autoArchiveProjects 归档处于完成状态超过指定周数的项目
+// 没有状态变更时间的历史数据以创建时间为准
+func autoArchiveProjects(db *sql.DB, statuses []string, afterWeeks int) (int64, error) {
+	cutoff := time.Now().AddDate(0, 0, -7*afterWeeks)
+
+	result, err := db.Exec(`
+		UPDATE projects
+		SET archived_at = CURRENT_TIMESTAMP, archived_by = 'system', version = version + 1
+		WHERE archived_at IS NULL AND deleted_at IS NULL
+		  AND status = ANY($1)
+		  AND COALESCE(status_changed_at, created_at) < $2`,
+		pq.Array(statuses), cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive projects: %w", err)
+	}
+
+	archived, _ := result.RowsAffected()
+	return archived, nil
+}
+
+// projectChildTables 永久删除项目时一并删除的子表，columns 为引用项目ID的列
+var projectChildTables = []struct {
+	table   string
+	columns []string
+}{
+	{"time_slots", []string{"project_id"}},
+	{"project_dependencies", []string{"blocker_id", "blocked_id"}},
+	{"project_milestones", []string{"project_id"}},
+	{"project_tasks", []string{"project_id"}},
+	{"project_labels", []string{"project_id"}},
+	{"project_attachments", []string{"project_id"}},
+	{"project_links", []string{"project_id"}},
+	{"project_risks", []string{"project_id"}},
+	{"project_health_history", []string{"project_id"}},
+	{"project_effort_estimates", []string{"project_id"}},
+}
+
+// queryIDs 执行只返回一列ID的查询
+func queryIDs(tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// purgeDeletedProjects 永久删除软删除时间超过保留天数的项目及其子表数据
+// 附件文件在事务提交后从存储中删除，删除失败只记录日志
+func purgeDeletedProjects(db *sql.DB, files storage.Storage, retentionDays int) (int64, error) {
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const expired = "SELECT id FROM projects WHERE deleted_at IS NOT NULL AND deleted_at < $1"
+	attachmentIDs, err := queryIDs(tx, "SELECT id FROM project_attachments WHERE project_id IN ("+expired+")", cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load attachments: %w", err)
+	}
+
+	for _, child := range projectChildTables {
+		conditions := make([]string, len(child.columns))
+		for i, column := range child.columns {
+			conditions[i] = column + " IN (" + expired + ")"
+		}
+		if _, err := tx.Exec("DELETE FROM "+child.table+" WHERE "+strings.Join(conditions, " OR "), cutoff); err != nil {
+			return 0, fmt.Errorf("failed to delete from %s: %w", child.table, err)
+		}
+	}
+
+	result, err := tx.Exec("DELETE FROM projects WHERE deleted_at IS NOT NULL AND deleted_at < $1", cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete projects: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for _, id := range attachmentIDs {
+		if err := files.Delete(id); err != nil {
+			log.Printf("Failed to delete attachment file %s: %v", id, err)
+		}
+	}
+
+	purged, _ := result.RowsAffected()
+	return purged, nil
+}
+
 func syncEmployeeData(db *sql.DB) error {
 	const maxRetries = 3
 	const retryDelay = time.Minute
@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"project-management-backend/internal/database"
+	"project-management-backend/internal/storage"
+)
+
+// openTestDB 连接 TEST_DATABASE_URL 指定的 PostgreSQL 测试库，未设置时跳过测试
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if !strings.HasPrefix(url, "postgresql://") {
+		t.Skip("TEST_DATABASE_URL is not a postgresql:// URL, skipping database test")
+	}
+	db, err := database.Initialize(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// insertTestProject 插入测试项目，deletedAt 为 nil 表示未删除，测试结束时删除
+func insertTestProject(t *testing.T, db *sql.DB, id string, deletedAt *time.Time) {
+	t.Helper()
+	_, err := db.Exec(`INSERT INTO projects (id, name, priority, status, deleted_at) VALUES ($1, $1, 'P1', '开发中', $2)`,
+		id, deletedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Exec("DELETE FROM projects WHERE id = $1", id) })
+}
+
+func projectExists(t *testing.T, db *sql.DB, id string) bool {
+	t.Helper()
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1)", id).Scan(&exists); err != nil {
+		t.Fatal(err)
+	}
+	return exists
+}
+
+func TestPurgeDeletedProjects(t *testing.T) {
+	db := openTestDB(t)
+	expired := time.Now().AddDate(0, 0, -31)
+	recent := time.Now().AddDate(0, 0, -1)
+	insertTestProject(t, db, "purge-expired", &expired)
+	insertTestProject(t, db, "purge-recent", &recent)
+	insertTestProject(t, db, "purge-live", nil)
+	for _, child := range []string{
+		`INSERT INTO time_slots (id, project_id, user_id, role_key) VALUES ('purge-slot', 'purge-expired', 'u1', 'qaTesters')`,
+		`INSERT INTO project_dependencies (id, blocker_id, blocked_id) VALUES ('purge-dep', 'purge-live', 'purge-expired')`,
+		`INSERT INTO project_milestones (id, project_id, name, planned_date) VALUES ('purge-ms', 'purge-expired', 'm', '2026-10-01')`,
+		`INSERT INTO project_tasks (id, project_id, title, status) VALUES ('purge-task', 'purge-expired', 't', '未开始')`,
+		`INSERT INTO project_labels (project_id, label_id) VALUES ('purge-expired', 'purge-label')`,
+		`INSERT INTO project_attachments (id, project_id, file_name, size, mime_type, checksum)
+		 VALUES ('purge-att', 'purge-expired', 'a.txt', 1, 'text/plain', 'x')`,
+		`INSERT INTO project_links (id, project_id, kind, url) VALUES ('purge-link', 'purge-expired', 'prd', 'https://example.com')`,
+		`INSERT INTO project_risks (id, project_id, description, probability, impact, status)
+		 VALUES ('purge-risk', 'purge-expired', 'r', '高', '高', '待处理')`,
+		`INSERT INTO project_health_history (id, project_id, health) VALUES ('purge-health', 'purge-expired', 'red')`,
+		`INSERT INTO project_effort_estimates (project_id, role_key, person_days) VALUES ('purge-expired', 'qaTesters', 5)`,
+	} {
+		if _, err := db.Exec(child); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := files.Save("purge-att", strings.NewReader("a")); err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := purgeDeletedProjects(db, files, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged < 1 {
+		t.Errorf("purged = %d, want at least 1", purged)
+	}
+	for id, want := range map[string]bool{"purge-expired": false, "purge-recent": true, "purge-live": true} {
+		if got := projectExists(t, db, id); got != want {
+			t.Errorf("project %s exists = %v, want %v", id, got, want)
+		}
+	}
+	// 子表中引用被删除项目的数据一并删除
+	for _, query := range []string{
+		"SELECT COUNT(*) FROM time_slots WHERE id = 'purge-slot'",
+		"SELECT COUNT(*) FROM project_dependencies WHERE id = 'purge-dep'",
+		"SELECT COUNT(*) FROM project_milestones WHERE id = 'purge-ms'",
+		"SELECT COUNT(*) FROM project_tasks WHERE id = 'purge-task'",
+		"SELECT COUNT(*) FROM project_labels WHERE project_id = 'purge-expired'",
+		"SELECT COUNT(*) FROM project_attachments WHERE id = 'purge-att'",
+		"SELECT COUNT(*) FROM project_links WHERE id = 'purge-link'",
+		"SELECT COUNT(*) FROM project_risks WHERE id = 'purge-risk'",
+		"SELECT COUNT(*) FROM project_health_history WHERE id = 'purge-health'",
+		"SELECT COUNT(*) FROM project_effort_estimates WHERE project_id = 'purge-expired'",
+	} {
+		var count int
+		if err := db.QueryRow(query).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("%s = %d, want 0", query, count)
+		}
+	}
+	// 附件文件从存储中删除
+	if _, err := files.Open("purge-att"); err != storage.ErrNotFound {
+		t.Errorf("Open(purge-att) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestAutoArchiveProjects(t *testing.T) {
+	db := openTestDB(t)
+	old := time.Now().AddDate(0, 0, -30)
+	recent := time.Now().AddDate(0, 0, -3)
+	for _, p := range []struct {
+		id, status string
+		changedAt  time.Time
+	}{
+		{"archive-done-old", "已完成", old},
+		{"archive-done-recent", "已完成", recent},
+		{"archive-active-old", "开发中", old},
+	} {
+		_, err := db.Exec(`INSERT INTO projects (id, name, priority, status, status_changed_at) VALUES ($1, $1, 'P1', $2, $3)`,
+			p.id, p.status, p.changedAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id := p.id
+		t.Cleanup(func() { db.Exec("DELETE FROM projects WHERE id = $1", id) })
+	}
+
+	if _, err := autoArchiveProjects(db, []string{"已完成"}, 2); err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]bool{"archive-done-old": true, "archive-done-recent": false, "archive-active-old": false} {
+		var archived bool
+		if err := db.QueryRow("SELECT archived_at IS NOT NULL FROM projects WHERE id = $1", id).Scan(&archived); err != nil {
+			t.Fatal(err)
+		}
+		if archived != want {
+			t.Errorf("project %s archived = %v, want %v", id, archived, want)
+		}
+	}
+}
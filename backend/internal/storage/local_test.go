@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalKeyValidation(t *testing.T) {
+	s, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key   string
+		valid bool
+	}{
+		{"att_1700000000_1", true},
+		{"ABC-def_123", true},
+		{"", false},
+		{"../etc/passwd", false},
+		{"a/b", false},
+		{"a.b", false},
+		{"..", false},
+		{"a b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			_, err := s.path(tt.key)
+			if (err == nil) != tt.valid {
+				t.Fatalf("path(%q) error = %v, valid %v", tt.key, err, tt.valid)
+			}
+			if tt.valid {
+				return
+			}
+			if err := s.Save(tt.key, strings.NewReader("x")); err == nil {
+				t.Error("Save accepted an invalid key")
+			}
+			if _, err := s.Open(tt.key); err == nil || errors.Is(err, ErrNotFound) {
+				t.Errorf("Open(%q) error = %v, want invalid key error", tt.key, err)
+			}
+			if err := s.Delete(tt.key); err == nil {
+				t.Error("Delete accepted an invalid key")
+			}
+		})
+	}
+}
+
+func TestLocalRoundTrip(t *testing.T) {
+	s, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Open("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Open missing object error = %v, want ErrNotFound", err)
+	}
+	if err := s.Save("obj", strings.NewReader("hello")); err != nil {
+		t.Fatal(err)
+	}
+	r, err := s.Open("obj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("content = %q, want %q", data, "hello")
+	}
+	if err := s.Delete("obj"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Open("obj"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open after Delete error = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete("obj"); err != nil {
+		t.Errorf("Delete of missing object error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotFound 对象不存在
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage 附件等文件的存储后端，key 由调用方生成且只含字母、数字、下划线、短横线
+type Storage interface {
+	// Save 写入对象，已存在时覆盖
+	Save(key string, r io.Reader) error
+	// Open 读取对象，不存在时返回 ErrNotFound
+	Open(key string) (io.ReadCloser, error)
+	// Delete 删除对象，不存在时不报错
+	Delete(key string) error
+}
+
+// New 按名称创建存储后端，目前支持 local（本地磁盘，dir 为存储目录）
+func New(backend, dir string) (Storage, error) {
+	switch backend {
+	case "", "local":
+		return NewLocal(dir)
+	}
+	return nil, fmt.Errorf("unknown storage backend %q", backend)
+}
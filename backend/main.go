@@ -5,7 +5,9 @@ import (
 	"project-management-backend/internal/api"
 	"project-management-backend/internal/config"
 	"project-management-backend/internal/database"
+	"project-management-backend/internal/middleware"
 	"project-management-backend/internal/scheduler"
+	"project-management-backend/internal/storage"
 )
 
 func main() {
@@ -19,11 +21,33 @@ func main() {
 	}
 	defer db.Close()
 
+	// 配置管理员
+	middleware.SetRoleMembers("admin", cfg.AdminUserIDs)
+	if len(cfg.AdminUserIDs) == 0 {
+		log.Println("ADMIN_USER_IDS not set, admin endpoints will reject all requests")
+	}
+
+	// 初始化附件存储
+	attachmentStorage, err := storage.New(cfg.AttachmentStorage, cfg.AttachmentDir)
+	if err != nil {
+		log.Fatal("Failed to initialize attachment storage:", err)
+	}
+
 	// 启动定时任务
-	scheduler.Start(db)
+	scheduler.Start(db, cfg, attachmentStorage)
+
+	// 解析节假日
+	holidays, err := api.NewHolidayCalendar(cfg.Holidays)
+	if err != nil {
+		log.Fatal("Invalid HOLIDAYS:", err)
+	}
 
 	// 启动 API 服务器
-	router := api.SetupRouter(db)
+	router := api.SetupRouter(db, api.AttachmentConfig{
+		Storage:      attachmentStorage,
+		MaxSize:      int64(cfg.AttachmentMaxSizeMB) << 20,
+		AllowedTypes: cfg.AttachmentAllowedTypes,
+	}, holidays)
 	log.Printf("Server starting on 0.0.0.0:%s", cfg.Port)
 	if err := router.Run("0.0.0.0:" + cfg.Port); err != nil {
 		log.Fatal("Failed to start server:", err)